@@ -0,0 +1,107 @@
+package main
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/logger"
+	"TelecomTask/internal/process"
+	"TelecomTask/internal/replay"
+	"TelecomTask/internal/scoreboard"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// boardHandler feeds replayed events into the processor and redraws the scoreboard as the simulated clock runs
+type boardHandler struct {
+	cfg       *config.Config
+	catalog   *i18n.Catalog
+	logger    *zap.Logger
+	processor *process.Processor
+	board     *scoreboard.Board
+	feedSize  int
+}
+
+func (h *boardHandler) Reset() {
+	h.processor = process.NewProcessor(h.cfg, h.logger)
+	h.board = scoreboard.New(h.cfg, h.catalog, h.feedSize)
+	h.processor.Observe(h.board.Record)
+}
+
+func (h *boardHandler) Handle(event process.Event) {
+	h.processor.Process(event)
+}
+
+// Tick raises events due by the simulated time and redraws the screen
+func (h *boardHandler) Tick(now time.Time) {
+	h.processor.Tick(now)
+	if err := h.board.Render(os.Stdout, now, h.processor.Competitors()); err != nil {
+		h.logger.Error("Error rendering scoreboard", zap.Error(err))
+	}
+}
+
+// runBoard replays recorded events and shows live standings with recent events in the terminal.
+// Logs go only to the files of the config, so that they do not break the screen
+func runBoard(args []string) {
+	flags := flag.NewFlagSet("board", flag.ExitOnError)
+	loadConfig := configFlags(flags)
+	eventsPath := flags.String("events", "events", "path to the recorded events file")
+	speed := flags.Float64("speed", 1, "replay speed, 1 means real time")
+	feedSize := flags.Int("feed", 10, "number of recent events shown")
+	locale := flags.String("locale", "", "language of the scoreboard, overrides config: en or ru")
+	_ = flags.Parse(args)
+
+	cfg := loadConfig()
+	catalog := loadCatalog(cfg, *locale)
+	boardLogger := newFileLogger(cfg)
+	defer func() {
+		_ = boardLogger.Sync()
+	}()
+	events, err := process.LoadEvents(*eventsPath)
+	if err != nil {
+		log.Fatal("Error loading events: ", err)
+		return
+	}
+	handler := &boardHandler{cfg: cfg, catalog: catalog, logger: boardLogger, feedSize: *feedSize}
+	handler.Reset()
+	replayer, err := replay.New(events, handler, *speed)
+	if err != nil {
+		log.Fatal("Error creating replay: ", err)
+		return
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	fmt.Print("\x1b[?25l")
+	defer fmt.Print("\x1b[?25h")
+	if err = replayer.Run(ctx); err != nil && err != context.Canceled {
+		fmt.Printf("Replay stopped: %v\n", err)
+	}
+	handler.processor.Flush()
+	handler.Tick(replayer.Now())
+}
+
+// newFileLogger creates logger writing only into log files of the config, without stdout and stderr
+func newFileLogger(cfg *config.Config) *zap.Logger {
+	logCfg := cfg.Log
+	logCfg.Outputs = nil
+	for _, output := range cfg.Log.Outputs {
+		if output != "stdout" && output != "stderr" {
+			logCfg.Outputs = append(logCfg.Outputs, output)
+		}
+	}
+	if len(logCfg.Outputs) == 0 {
+		return zap.NewNop()
+	}
+	fileLogger, err := logger.New(logCfg)
+	if err != nil {
+		log.Fatal("Error creating logger: ", err)
+	}
+	return fileLogger
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"TelecomTask/internal/jury"
+	"TelecomTask/internal/pipeline"
+	"TelecomTask/internal/process"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// runExplain processes the race and prints the report line of the competitor with the events behind every figure of it
+func runExplain(args []string) {
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	loadConfig := configFlags(flags)
+	eventsPath := flags.String("events", "events", "path to the events file")
+	decisionsPath := flags.String("decisions", "", "path to the jury decisions applied on top of the processed race")
+	competitor := flags.Int("competitor", 0, "competitor to explain")
+	locale := flags.String("locale", "", "language of the explanation, overrides config: en or ru")
+	_ = flags.Parse(args)
+	cfg := loadConfig()
+	if *competitor == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	catalog := loadCatalog(cfg, *locale)
+	events, err := process.LoadEvents(*eventsPath)
+	if err != nil {
+		log.Fatal("Error loading events: ", err)
+		return
+	}
+	competitors, reports := pipeline.Run(cfg, events, zap.NewNop())
+	if *decisionsPath != "" {
+		decisions, err := jury.Load(*decisionsPath)
+		if err != nil {
+			log.Fatal("Error loading jury decisions: ", err)
+			return
+		}
+		reports = pipeline.Judge(cfg, competitors, decisions, zap.NewNop())
+	}
+
+	comp, ok := competitors[*competitor]
+	if !ok {
+		log.Fatal(fmt.Sprintf("Competitor %d not found", *competitor))
+		return
+	}
+	for _, report := range reports {
+		if report.CompetitorID != comp.ID {
+			continue
+		}
+		if err = pipeline.WriteExplanation(os.Stdout, catalog, comp, report, events); err != nil {
+			log.Fatal("Error writing explanation: ", err)
+		}
+	}
+}
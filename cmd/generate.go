@@ -0,0 +1,59 @@
+package main
+
+import (
+	"TelecomTask/internal/generator"
+	"flag"
+	"log"
+	"os"
+)
+
+// runGenerate writes synthetic events file for the competition config
+func runGenerate(args []string) {
+	defaults := generator.DefaultParams()
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	loadConfig := configFlags(flags)
+	out := flags.String("out", "", "path to the generated events file, stdout if empty")
+	competitors := flags.Int("competitors", defaults.Competitors, "number of competitors")
+	seed := flags.Int64("seed", defaults.Seed, "random seed, the same seed gives the same race")
+	speed := flags.Float64("speed", defaults.SpeedMean, "mean ski speed, m/s")
+	speedStdDev := flags.Float64("speed-stddev", defaults.SpeedStdDev, "standard deviation of ski speed, m/s")
+	penaltySpeed := flags.Float64("penalty-speed", defaults.PenaltySpeed, "mean speed on penalty laps, m/s")
+	accuracy := flags.Float64("accuracy", defaults.Accuracy, "probability of hitting a target")
+	dnf := flags.Float64("dnf", defaults.DNFRate, "probability of not finishing the race")
+	late := flags.Float64("late", defaults.LateStartRate, "probability of a late start")
+	_ = flags.Parse(args)
+
+	cfg := loadConfig()
+	events, err := generator.Generate(cfg, generator.Params{
+		Competitors:   *competitors,
+		Seed:          *seed,
+		SpeedMean:     *speed,
+		SpeedStdDev:   *speedStdDev,
+		PenaltySpeed:  *penaltySpeed,
+		Accuracy:      *accuracy,
+		DNFRate:       *dnf,
+		LateStartRate: *late,
+	})
+	if err != nil {
+		log.Fatal("Error generating events: ", err)
+		return
+	}
+
+	output := os.Stdout
+	if *out != "" {
+		output, err = os.Create(*out)
+		if err != nil {
+			log.Fatal("error creating events file: ", err)
+			return
+		}
+		defer func(file *os.File) {
+			err = file.Close()
+			if err != nil {
+				log.Fatal("error closing events file", err)
+			}
+		}(output)
+	}
+	if err = generator.Write(output, events); err != nil {
+		log.Fatal("Error writing events: ", err)
+	}
+}
@@ -2,78 +2,196 @@ package main
 
 import (
 	"TelecomTask/internal/config"
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/journal"
+	"TelecomTask/internal/jury"
+	"TelecomTask/internal/logger"
+	"TelecomTask/internal/pipeline"
 	"TelecomTask/internal/process"
+	"TelecomTask/internal/storage"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+
+	"go.uber.org/zap"
 )
 
 func main() {
-	cfg, err := config.New("./config/config.json")
-	if err != nil {
-		log.Fatal("Error loading config: ", err)
-		return
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "replay":
+			runReplay(os.Args[2:])
+			return
+		case "generate":
+			runGenerate(os.Args[2:])
+			return
+		case "results":
+			runResults(os.Args[2:])
+			return
+		case "season":
+			runSeason(os.Args[2:])
+			return
+		case "explain":
+			runExplain(os.Args[2:])
+			return
+		case "board":
+			runBoard(os.Args[2:])
+			return
+		case "standings":
+			runStandings(os.Args[2:])
+			return
+		}
 	}
+
+	loadConfig := configFlags(flag.CommandLine)
+	locale := flag.String("locale", "", "language of logs and report, overrides config: en or ru")
+	journalPath := flag.String("journal", "", "path to the journal of processed events, the race is restored from it after restart")
+	dbPath := flag.String("db", "", "path to the SQLite database to store the race in")
+	raceName := flag.String("race", "", "name of the race in the database, events file name if empty")
+	decisionsPath := flag.String("decisions", "", "path to the jury decisions applied on top of the processed race")
+	flag.Parse()
+
+	cfg := loadConfig()
+	catalog := loadCatalog(cfg, *locale)
+	raceLogger := newLogger(cfg)
+	defer func() {
+		_ = raceLogger.Sync()
+	}()
 	events, err := process.LoadEvents("events")
 	if err != nil {
-		log.Fatal("Error loading events: ", err)
+		raceLogger.Fatal("Error loading events", zap.Error(err))
 		return
 	}
-	competitors, outgoingEvents := process.Events(cfg, events)
-	reports := process.GenerateReport(competitors, cfg)
 
-	logFile, err := os.Create("output.log")
-	if err != nil {
-		log.Fatal("error creating log file: ", err)
-		return
-	}
-	defer func(logFile *os.File) {
-		err = logFile.Close()
+	var competitors map[int]*process.Competitor
+	var reports []process.Report
+	if *journalPath == "" {
+		competitors, reports = pipeline.Run(cfg, events, raceLogger)
+	} else {
+		j := openJournal(*journalPath, raceLogger)
+		defer closeJournal(j, raceLogger)
+		competitors, reports, err = pipeline.RunJournaled(cfg, events, raceLogger, j)
 		if err != nil {
-			log.Fatal("error closing log file", err)
+			raceLogger.Fatal("Error processing events", zap.Error(err))
+			return
 		}
-	}(logFile)
-	log.SetOutput(logFile)
-	for _, event := range events {
-		switch event.EventID {
-		case 1:
-			process.LogEvent(event, fmt.Sprintf("The competitor(%d) registered", event.CompetitorID))
-		case 2:
-			process.LogEvent(event, fmt.Sprintf("The start time for competitor(%d) was set by a draw to %s", event.CompetitorID, event.ExtraParams[0]))
-		case 3:
-			process.LogEvent(event, fmt.Sprintf("The competitor(%d) is on the start line", event.CompetitorID))
-		case 4:
-			process.LogEvent(event, fmt.Sprintf("The competitor(%d) has started", event.CompetitorID))
-		case 5:
-			process.LogEvent(event, fmt.Sprintf("The competitor(%d) is on the firing range(%s)", event.CompetitorID, event.ExtraParams[0]))
-		case 6:
-			process.LogEvent(event, fmt.Sprintf("The target(%s) has been hit by competitor(%d)", event.ExtraParams[0], event.CompetitorID))
-		case 7:
-			process.LogEvent(event, fmt.Sprintf("The competitor(%d) left the firing range", event.CompetitorID))
-		case 8:
-			process.LogEvent(event, fmt.Sprintf("The competitor(%d) entered the penalty laps", event.CompetitorID))
-		case 9:
-			process.LogEvent(event, fmt.Sprintf("The competitor(%d) left the penalty laps", event.CompetitorID))
-		case 10:
-			process.LogEvent(event, fmt.Sprintf("The competitor(%d) ended the main lap", event.CompetitorID))
-		case 11:
-			process.LogEvent(event, fmt.Sprintf("The competitor(%d) can't continue: %s", event.CompetitorID, strings.Join(event.ExtraParams, " ")))
+	}
+	if *decisionsPath != "" {
+		decisions, err := jury.Load(*decisionsPath)
+		if err != nil {
+			raceLogger.Fatal("Error loading jury decisions", zap.Error(err))
+			return
 		}
+		reports = pipeline.Judge(cfg, competitors, decisions, raceLogger)
 	}
-	for _, event := range outgoingEvents {
-		switch event.EventID {
-		case 32:
-			process.LogEvent(event, fmt.Sprintf("The competitor(%d) is disqualified", event.CompetitorID))
-		case 33:
-			process.LogEvent(event, fmt.Sprintf("The competitor(%d) has finished", event.CompetitorID))
+	if *dbPath != "" {
+		if *raceName == "" {
+			*raceName = "events"
 		}
+		saveRace(*dbPath, *raceName, cfg, events, competitors, reports, raceLogger)
 	}
 
 	// Вывод итогового отчета
 	fmt.Printf("\n")
-	for _, r := range reports {
-		fmt.Printf("[%s] %d %v %s %.3f %s\n",
-			r.TotalTime, r.CompetitorID, r.LapDetails, r.PenaltyTime, r.PenaltySpeed, r.HitsShots)
+	if err = pipeline.WriteReport(os.Stdout, catalog, reports); err != nil {
+		raceLogger.Fatal("Error writing report", zap.Error(err))
+	}
+}
+
+// newLogger creates logger from config and makes it global, so every message goes to the configured sinks
+func newLogger(cfg *config.Config) *zap.Logger {
+	raceLogger, err := logger.New(cfg.Log)
+	if err != nil {
+		log.Fatal("Error creating logger: ", err)
+	}
+	zap.ReplaceGlobals(raceLogger)
+	return raceLogger
+}
+
+// overrides collects repeated key=value flags overriding config fields
+type overrides []string
+
+func (o *overrides) String() string {
+	return strings.Join(*o, " ")
+}
+
+func (o *overrides) Set(value string) error {
+	*o = append(*o, value)
+	return nil
+}
+
+// configFlags registers flags of the competition config: path to the config file, overrides of its fields and
+// printing of the effective config. The returned function loads the config after the flags are parsed,
+// with -print-config it prints the effective config and exits
+func configFlags(flags *flag.FlagSet) func() *config.Config {
+	path := flags.String("config", "./config/config.json", "path to the competition config: .json, .yaml, .yml or .toml")
+	var sets overrides
+	flags.Var(&sets, "set", "override of a config field as key=value, e.g. laps=3 or log.level=debug, can be repeated")
+	printConfig := flags.Bool("print-config", false, "print the effective config and exit")
+	return func() *config.Config {
+		cfg, err := config.Load(*path, sets)
+		if err != nil {
+			log.Fatal("Error loading config: ", err)
+		}
+		if *printConfig {
+			if err = cfg.Write(os.Stdout); err != nil {
+				log.Fatal("Error printing config: ", err)
+			}
+			os.Exit(0)
+		}
+		return cfg
+	}
+}
+
+// loadCatalog returns message catalog of the locale from flag or, if the flag is empty, from config
+func loadCatalog(cfg *config.Config, locale string) *i18n.Catalog {
+	if locale != "" {
+		cfg.Locale = locale
+	}
+	catalog, err := i18n.Get(cfg.Locale)
+	if err != nil {
+		log.Fatal("Error loading locale: ", err)
+	}
+	return catalog
+}
+
+// openJournal opens journal of processed events
+func openJournal(path string, raceLogger *zap.Logger) *journal.Journal {
+	j, err := journal.Open(path)
+	if err != nil {
+		raceLogger.Fatal("Error opening journal", zap.Error(err))
+	}
+	if len(j.Events()) > 0 {
+		raceLogger.Info("Restoring race state from journal", zap.String("journal", path), zap.Int("events", len(j.Events())))
+	}
+	return j
+}
+
+// closeJournal closes journal of processed events
+func closeJournal(j *journal.Journal, raceLogger *zap.Logger) {
+	if err := j.Close(); err != nil {
+		raceLogger.Error("Error closing journal", zap.Error(err))
+	}
+}
+
+// saveRace stores the race with its results in the database
+func saveRace(path, name string, cfg *config.Config, events []process.Event,
+	competitors map[int]*process.Competitor, reports []process.Report, raceLogger *zap.Logger) {
+	store, err := storage.Open(path)
+	if err != nil {
+		raceLogger.Fatal("Error opening database", zap.Error(err))
+		return
+	}
+	defer func() {
+		if err = store.Close(); err != nil {
+			raceLogger.Error("Error closing database", zap.Error(err))
+		}
+	}()
+	if err = store.SaveRace(name, cfg, events, competitors, reports); err != nil {
+		raceLogger.Error("Error saving race", zap.Error(err))
+		return
 	}
+	raceLogger.Info("Race saved to database", zap.String("db", path), zap.String("race", name))
 }
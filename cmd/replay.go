@@ -0,0 +1,177 @@
+package main
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/journal"
+	"TelecomTask/internal/pipeline"
+	"TelecomTask/internal/process"
+	"TelecomTask/internal/replay"
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// raceHandler feeds replayed events into the processor, which writes them into the output log.
+// With journal every event is recorded before processing
+type raceHandler struct {
+	cfg       *config.Config
+	processor *process.Processor
+	logger    *zap.Logger
+	journal   *journal.Journal
+	// recovered is the number of upcoming events which are already restored from the journal
+	recovered int
+}
+
+func (h *raceHandler) Reset() {
+	h.processor = process.NewProcessor(h.cfg, h.logger)
+	h.recovered = 0
+	if h.journal != nil {
+		if err := h.journal.Truncate(); err != nil {
+			h.logger.Error("Error truncating journal", zap.Error(err))
+		}
+	}
+}
+
+// restore processes events recorded in the journal, replayer skips them later
+func (h *raceHandler) restore() {
+	for _, event := range h.journal.Events() {
+		h.processor.Process(event)
+	}
+	h.recovered = len(h.journal.Events())
+}
+
+func (h *raceHandler) Handle(event process.Event) {
+	if h.recovered > 0 {
+		h.recovered--
+		return
+	}
+	if h.journal != nil {
+		if err := h.journal.Append(event); err != nil {
+			h.logger.Error("Error appending event to journal", append(process.EventFields(event), zap.Error(err))...)
+		}
+	}
+	h.processor.Process(event)
+}
+
+// Tick lets the processor raise events which are due by the simulated time, such as did not start
+func (h *raceHandler) Tick(now time.Time) {
+	h.processor.Tick(now)
+}
+
+// runReplay replays recorded events file with simulated clock.
+// While replaying, commands are read from stdin: pause, resume, speed N, seek HH:MM:SS.sss, quit
+func runReplay(args []string) {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	loadConfig := configFlags(flags)
+	eventsPath := flags.String("events", "events", "path to the recorded events file")
+	speed := flags.Float64("speed", 1, "replay speed, 1 means real time")
+	from := flags.String("from", "", "simulated time to start replay from, HH:MM:SS.sss")
+	locale := flags.String("locale", "", "language of logs and report, overrides config: en or ru")
+	journalPath := flags.String("journal", "", "path to the journal of processed events, the replay continues from it after restart")
+	_ = flags.Parse(args)
+
+	cfg := loadConfig()
+	catalog := loadCatalog(cfg, *locale)
+	raceLogger := newLogger(cfg)
+	defer func() {
+		_ = raceLogger.Sync()
+	}()
+	events, err := process.LoadEvents(*eventsPath)
+	if err != nil {
+		raceLogger.Fatal("Error loading events", zap.Error(err))
+		return
+	}
+	handler := &raceHandler{cfg: cfg, logger: raceLogger, processor: process.NewProcessor(cfg, raceLogger)}
+	replayer, err := replay.New(events, handler, *speed)
+	if err != nil {
+		raceLogger.Fatal("Error creating replay", zap.Error(err))
+		return
+	}
+	if *journalPath != "" {
+		handler.journal = openJournal(*journalPath, raceLogger)
+		defer closeJournal(handler.journal, raceLogger)
+		if _, err = journal.Remaining(handler.journal.Events(), events); err != nil {
+			raceLogger.Fatal("Error restoring from journal", zap.Error(err))
+			return
+		}
+		handler.restore()
+		if recorded := handler.journal.Events(); len(recorded) > 0 && *from == "" {
+			*from = recorded[len(recorded)-1].Time
+		}
+	}
+	if *from != "" {
+		t, err := replayer.ParseTime(*from)
+		if err != nil {
+			raceLogger.Fatal("Error parsing start time", zap.Error(err))
+			return
+		}
+		replayer.Seek(t)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go readReplayCommands(replayer, cancel)
+
+	if err = replayer.Run(ctx); err != nil && err != context.Canceled {
+		fmt.Printf("Replay stopped: %v\n", err)
+	}
+	handler.processor.Flush()
+	fmt.Printf("\n")
+	if err = pipeline.WriteReport(os.Stdout, catalog, process.GenerateReport(handler.processor.Competitors(), cfg)); err != nil {
+		raceLogger.Fatal("Error writing report", zap.Error(err))
+	}
+}
+
+// readReplayCommands reads replay control commands from stdin
+func readReplayCommands(replayer *replay.Replayer, cancel context.CancelFunc) {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "pause":
+			replayer.Pause()
+		case "resume":
+			replayer.Resume()
+		case "speed":
+			if len(fields) < 2 {
+				fmt.Println("usage: speed N")
+				continue
+			}
+			speed, err := strconv.ParseFloat(fields[1], 64)
+			if err == nil {
+				err = replayer.SetSpeed(speed)
+			}
+			if err != nil {
+				fmt.Printf("invalid speed: %v\n", err)
+			}
+		case "seek":
+			if len(fields) < 2 {
+				fmt.Println("usage: seek HH:MM:SS.sss")
+				continue
+			}
+			t, err := replayer.ParseTime(fields[1])
+			if err != nil {
+				fmt.Printf("invalid time: %v\n", err)
+				continue
+			}
+			replayer.Seek(t)
+		case "now":
+			fmt.Println(replayer.Now().Format("15:04:05.000"))
+		case "quit":
+			cancel()
+			return
+		default:
+			fmt.Println("commands: pause, resume, speed N, seek HH:MM:SS.sss, now, quit")
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/pipeline"
+	"TelecomTask/internal/process"
+	"TelecomTask/internal/storage"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// runResults prints results stored in the database: standings of a race, results of a competitor or list of races
+func runResults(args []string) {
+	flags := flag.NewFlagSet("results", flag.ExitOnError)
+	dbPath := flags.String("db", "results.db", "path to the SQLite database")
+	race := flags.String("race", "", "print standings of the race")
+	competitor := flags.Int("competitor", 0, "print results of the competitor in all races")
+	locale := flags.String("locale", i18n.Default, "language of the report: en or ru")
+	_ = flags.Parse(args)
+
+	catalog, err := i18n.Get(*locale)
+	if err != nil {
+		log.Fatal("Error loading locale: ", err)
+		return
+	}
+	store, err := storage.Open(*dbPath)
+	if err != nil {
+		log.Fatal("Error opening database: ", err)
+		return
+	}
+	defer func() {
+		if err = store.Close(); err != nil {
+			log.Fatal("Error closing database: ", err)
+		}
+	}()
+
+	switch {
+	case *race != "":
+		results, err := store.Standings(*race)
+		if err != nil {
+			log.Fatal("Error loading standings: ", err)
+			return
+		}
+		reports := make([]process.Report, len(results))
+		for i, result := range results {
+			reports[i] = result.Report
+		}
+		if err = pipeline.WriteReport(os.Stdout, catalog, reports); err != nil {
+			log.Fatal("Error writing report: ", err)
+		}
+	case *competitor != 0:
+		results, err := store.CompetitorResults(*competitor)
+		if err != nil {
+			log.Fatal("Error loading results: ", err)
+			return
+		}
+		for _, result := range results {
+			if result.Position == 0 {
+				fmt.Printf("%s %s\n", result.Race, pipeline.FormatReport(catalog, result.Report))
+				continue
+			}
+			fmt.Printf("%s #%d %s\n", result.Race, result.Position, pipeline.FormatReport(catalog, result.Report))
+		}
+	default:
+		races, err := store.Races()
+		if err != nil {
+			log.Fatal("Error loading races: ", err)
+			return
+		}
+		for _, name := range races {
+			fmt.Println(name)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/pipeline"
+	"TelecomTask/internal/process"
+	"TelecomTask/internal/season"
+	"TelecomTask/internal/storage"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// runSeason computes cumulative standings of several races. Races are report files written by the default mode
+// or, with -db, names of races stored in the database
+func runSeason(args []string) {
+	flags := flag.NewFlagSet("season", flag.ExitOnError)
+	pointsPath := flags.String("points", "config/points.json", "path to the points table")
+	dbPath := flags.String("db", "", "read races from the SQLite database instead of report files")
+	category := flags.String("category", "", "compute standings of the category only")
+	locale := flags.String("locale", i18n.Default, "language of the standings: en or ru")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: telecomtask season [flags] RACE...")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	catalog, err := i18n.Get(*locale)
+	if err != nil {
+		log.Fatal("Error loading locale: ", err)
+		return
+	}
+	table, err := season.LoadPointsTable(*pointsPath)
+	if err != nil {
+		log.Fatal("Error loading points table: ", err)
+		return
+	}
+	var races []season.Race
+	if *dbPath != "" {
+		races, err = loadStoredRaces(*dbPath, flags.Args())
+	} else {
+		races, err = loadReportFiles(flags.Args())
+	}
+	if err != nil {
+		log.Fatal("Error loading races: ", err)
+		return
+	}
+
+	standings, err := season.Compute(table, races, *category)
+	if err != nil {
+		log.Fatal("Error computing standings: ", err)
+		return
+	}
+	if err = season.WriteStandings(os.Stdout, catalog, standings); err != nil {
+		log.Fatal("Error writing standings: ", err)
+	}
+}
+
+// loadReportFiles reads resulting tables of races from report files
+func loadReportFiles(paths []string) ([]season.Race, error) {
+	races := make([]season.Race, 0, len(paths))
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("loadReportFiles: %w", err)
+		}
+		reports, err := pipeline.ReadReport(file)
+		_ = file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("loadReportFiles: %s: %w", path, err)
+		}
+		races = append(races, season.Race{Name: filepath.Base(path), Reports: reports})
+	}
+	return races, nil
+}
+
+// loadStoredRaces reads resulting tables of races from the database
+func loadStoredRaces(dbPath string, names []string) ([]season.Race, error) {
+	store, err := storage.Open(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("loadStoredRaces: %w", err)
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+	races := make([]season.Race, 0, len(names))
+	for _, name := range names {
+		results, err := store.Standings(name)
+		if err != nil {
+			return nil, fmt.Errorf("loadStoredRaces: %w", err)
+		}
+		if len(results) == 0 {
+			return nil, fmt.Errorf("loadStoredRaces: race %s not found", name)
+		}
+		reports := make([]process.Report, len(results))
+		for i, result := range results {
+			reports[i] = result.Report
+		}
+		races = append(races, season.Race{Name: name, Reports: reports})
+	}
+	return races, nil
+}
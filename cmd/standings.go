@@ -0,0 +1,40 @@
+package main
+
+import (
+	"TelecomTask/internal/live"
+	"TelecomTask/internal/process"
+	"TelecomTask/internal/timestamp"
+	"flag"
+	"log"
+	"os"
+)
+
+// runStandings processes the race and prints the standings at the given moment of it
+func runStandings(args []string) {
+	flags := flag.NewFlagSet("standings", flag.ExitOnError)
+	loadConfig := configFlags(flags)
+	eventsPath := flags.String("events", "events", "path to the events file")
+	at := flags.String("at", "", "moment of the race in HH:MM:SS.sss or RFC 3339 format")
+	locale := flags.String("locale", "", "language of the standings, overrides config: en or ru")
+	_ = flags.Parse(args)
+	cfg := loadConfig()
+	if *at == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	catalog := loadCatalog(cfg, *locale)
+	moment, err := timestamp.Resolve(*at, cfg.TimeReference())
+	if err != nil {
+		log.Fatal("Error parsing moment: ", err)
+		return
+	}
+	events, err := process.LoadEvents(*eventsPath)
+	if err != nil {
+		log.Fatal("Error loading events: ", err)
+		return
+	}
+	if err = live.WriteStandings(os.Stdout, catalog, live.Standings(cfg, events, moment)); err != nil {
+		log.Fatal("Error writing standings: ", err)
+	}
+}
@@ -1,39 +1,318 @@
 package config
 
 import (
+	"TelecomTask/internal/timestamp"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Laps        int    `json:"laps"`
-	LapLen      int    `json:"lapLen"`
-	PenaltyLen  int    `json:"penaltyLen"`
-	FiringLines int    `json:"firingLines"`
-	Start       string `json:"start"`
-	StartDelta  string `json:"startDelta"`
+	Laps        int       `json:"laps"`
+	LapLen      int       `json:"lapLen"`
+	PenaltyLen  int       `json:"penaltyLen"`
+	FiringLines int       `json:"firingLines"`
+	Start       string    `json:"start"`
+	StartDelta  string    `json:"startDelta"`
+	Locale      string    `json:"locale"` // language of logs and reports: en or ru
+	Log         LogConfig `json:"log"`
+	// Sources are clock corrections of timing devices by the source tag of events
+	Sources map[string]SourceConfig `json:"sources"`
+	// ReorderWindow is how long events are held back to be sorted by time, e.g. "5s". Empty disables reordering
+	ReorderWindow string `json:"reorderWindow"`
+	// DuplicateWindow is how close in time the same event must come again to be dropped as a duplicate,
+	// e.g. "1s". Empty means only exact duplicates are dropped
+	DuplicateWindow string `json:"duplicateWindow"`
+	// LateStart is the policy for competitors who start more than StartDelta after the drawn start time,
+	// disqualification by default
+	LateStart StartPolicy `json:"lateStart"`
+	// EarlyStart is the policy for competitors who start before the drawn start time,
+	// by default the start is accepted as is
+	EarlyStart StartPolicy `json:"earlyStart"`
+	// TimingReference is the moment the first lap and the total time are measured from:
+	// TimingScheduled, TimingActual or TimingGate. Empty means TimingActual
+	TimingReference string `json:"timingReference"`
 }
 
+// Timing references
+const (
+	// TimingScheduled measures time from the start time drawn for the competitor
+	TimingScheduled = "scheduled"
+	// TimingActual measures time from the start of the competitor
+	TimingActual = "actual"
+	// TimingGate measures time from the moment the competitor passed the start gate onto the start line
+	TimingGate = "gate"
+)
+
+// Start policy actions
+const (
+	// StartDisqualify disqualifies the competitor
+	StartDisqualify = "disqualify"
+	// StartPenalty adds penalty time to the total time of the competitor
+	StartPenalty = "penalty"
+	// StartDrawn times the competitor from the drawn start time instead of the actual start
+	StartDrawn = "drawn"
+)
+
+// StartPolicy describes what happens to a competitor who starts out of time
+type StartPolicy struct {
+	// Action is one of StartDisqualify, StartPenalty or StartDrawn, empty means the default of the policy
+	Action string `json:"action"`
+	// Penalty is the time added by StartPenalty, e.g. "1m"
+	Penalty string `json:"penalty"`
+}
+
+// PenaltyDuration returns parsed penalty time of the policy
+func (s StartPolicy) PenaltyDuration() (time.Duration, error) {
+	if s.Penalty == "" {
+		return 0, nil
+	}
+	penalty, err := time.ParseDuration(s.Penalty)
+	if err != nil {
+		return 0, fmt.Errorf("PenaltyDuration: %w", err)
+	}
+	if penalty < 0 {
+		return 0, fmt.Errorf("PenaltyDuration: penalty must not be negative: %s", s.Penalty)
+	}
+	return penalty, nil
+}
+
+// validate checks the action and the penalty of the policy
+func (s StartPolicy) validate() error {
+	switch s.Action {
+	case "", StartDisqualify, StartDrawn:
+	case StartPenalty:
+		if s.Penalty == "" {
+			return fmt.Errorf("validate: penalty action requires penalty time")
+		}
+	default:
+		return fmt.Errorf("validate: unknown start policy action %s", s.Action)
+	}
+	if _, err := s.PenaltyDuration(); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// SourceConfig describes clock of a timing device
+type SourceConfig struct {
+	// Offset is how much the device clock is ahead of the reference clock, e.g. "1.5s" or "-200ms".
+	// It is subtracted from times of the device events
+	Offset string `json:"offset"`
+	// TimeZone is IANA name of the zone of device times written without zone, UTC if empty.
+	// Zone offsets depend on the date, so a time zone requires the start with date
+	TimeZone string `json:"timeZone"`
+}
+
+// OffsetDuration returns parsed clock offset of the device
+func (s SourceConfig) OffsetDuration() (time.Duration, error) {
+	if s.Offset == "" {
+		return 0, nil
+	}
+	offset, err := time.ParseDuration(s.Offset)
+	if err != nil {
+		return 0, fmt.Errorf("OffsetDuration: %w", err)
+	}
+	return offset, nil
+}
+
+// Location returns time zone of the device
+func (s SourceConfig) Location() (*time.Location, error) {
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("Location: %w", err)
+	}
+	return location, nil
+}
+
+// LogConfig describes where and how logs are written
+type LogConfig struct {
+	Level   string   `json:"level"`   // debug, info, warn or error
+	Format  string   `json:"format"`  // console or json
+	Outputs []string `json:"outputs"` // stdout, stderr or file paths
+}
+
+// DefaultLogConfig returns log config used when it is not set in config file
+func DefaultLogConfig() LogConfig {
+	return LogConfig{
+		Level:   "info",
+		Format:  "console",
+		Outputs: []string{"stdout", "output.log"},
+	}
+}
+
+// New reads config file in JSON, YAML or TOML format, the format is detected by the file extension
 func New(filename string) (*Config, error) {
-	file, err := os.Open(filename)
+	fields, err := readFields(filename)
+	if err != nil {
+		return nil, fmt.Errorf("New: %w", err)
+	}
+	config, err := decode(fields)
 	if err != nil {
-		return nil, fmt.Errorf("New: error opening file: %w", err)
+		return nil, fmt.Errorf("New: %w", err)
 	}
-	defer func(file *os.File) {
-		err = file.Close()
-		if err != nil {
-			fmt.Printf("New: error closing file: %s", err.Error())
+	return config, nil
+}
+
+// Load reads config file the same way as New, then overrides its fields by environment variables and then
+// by key=value overrides, e.g. "laps=3" or "log.level=debug"
+func Load(filename string, overrides []string) (*Config, error) {
+	fields, err := readFields(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Load: %w", err)
+	}
+	if err = overrideFromEnv(fields, os.LookupEnv); err != nil {
+		return nil, fmt.Errorf("Load: %w", err)
+	}
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("Load: override must be key=value: %s", override)
+		}
+		if err = set(fields, key, value); err != nil {
+			return nil, fmt.Errorf("Load: %w", err)
 		}
-	}(file)
-	var config Config
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&config)
+	}
+	config, err := decode(fields)
+	if err != nil {
+		return nil, fmt.Errorf("Load: %w", err)
+	}
+	return config, nil
+}
+
+// decode fills config with defaults and the fields read from the file and validates it
+func decode(fields map[string]any) (*Config, error) {
+	data, err := json.Marshal(fields)
 	if err != nil {
-		return nil, fmt.Errorf("New: error decoding file: %w", err)
+		return nil, fmt.Errorf("decode: %w", err)
 	}
-	if config.FiringLines <= 0 || config.Laps <= 0 || config.LapLen <= 0 || config.PenaltyLen <= 0 {
-		return nil, fmt.Errorf("New: invalid config: some fields must be positive")
+	config := Config{Locale: "en", Log: DefaultLogConfig()}
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("decode: error decoding config: %w", err)
+	}
+	if err = config.validate(); err != nil {
+		return nil, fmt.Errorf("decode: invalid config: %w", err)
 	}
 	return &config, nil
 }
+
+// validate checks that all fields of the config have valid values
+func (c *Config) validate() error {
+	if c.FiringLines <= 0 || c.Laps <= 0 || c.LapLen <= 0 || c.PenaltyLen <= 0 {
+		return fmt.Errorf("some fields must be positive")
+	}
+	if c.Log.Format != "console" && c.Log.Format != "json" {
+		return fmt.Errorf("unknown log format %s", c.Log.Format)
+	}
+	if _, err := c.ReorderWindowDuration(); err != nil {
+		return err
+	}
+	if _, err := c.DuplicateWindowDuration(); err != nil {
+		return err
+	}
+	switch c.TimingReference {
+	case "", TimingScheduled, TimingActual, TimingGate:
+	default:
+		return fmt.Errorf("unknown timing reference %s", c.TimingReference)
+	}
+	if err := c.LateStart.validate(); err != nil {
+		return fmt.Errorf("late start: %w", err)
+	}
+	if err := c.EarlyStart.validate(); err != nil {
+		return fmt.Errorf("early start: %w", err)
+	}
+	for name, source := range c.Sources {
+		if _, err := source.OffsetDuration(); err != nil {
+			return fmt.Errorf("source %s: %w", name, err)
+		}
+		if _, err := source.Location(); err != nil {
+			return fmt.Errorf("source %s: %w", name, err)
+		}
+		if _, dated, _ := timestamp.Parse(c.Start); source.TimeZone != "" && !dated {
+			return fmt.Errorf("source %s: time zone requires start with date", name)
+		}
+	}
+	return nil
+}
+
+// Write writes the effective config in JSON, all fields are written including defaults and empty ones
+func (c *Config) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(c); err != nil {
+		return fmt.Errorf("Write: %w", err)
+	}
+	return nil
+}
+
+// StartTime returns parsed time of the competition start, it is either time of day or time with date
+func (c *Config) StartTime() (time.Time, error) {
+	start, _, err := timestamp.Parse(c.Start)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("StartTime: error parsing start: %w", err)
+	}
+	return start, nil
+}
+
+// TimeReference returns reference for event times without date: with dated start they belong to the start day
+func (c *Config) TimeReference() time.Time {
+	start, dated, err := timestamp.Parse(c.Start)
+	if err != nil {
+		return time.Time{}
+	}
+	return timestamp.Day(start, dated)
+}
+
+// StartDeltaDuration returns parsed interval between competitors' starts
+func (c *Config) StartDeltaDuration() (time.Duration, error) {
+	delta, err := time.Parse("15:04:05", c.StartDelta)
+	if err != nil {
+		return 0, fmt.Errorf("StartDeltaDuration: error parsing start delta: %w", err)
+	}
+	return delta.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)), nil
+}
+
+// ReorderWindowDuration returns parsed reorder window, zero if reordering is disabled
+func (c *Config) ReorderWindowDuration() (time.Duration, error) {
+	if c.ReorderWindow == "" {
+		return 0, nil
+	}
+	window, err := time.ParseDuration(c.ReorderWindow)
+	if err != nil {
+		return 0, fmt.Errorf("ReorderWindowDuration: %w", err)
+	}
+	if window < 0 {
+		return 0, fmt.Errorf("ReorderWindowDuration: window must not be negative: %s", c.ReorderWindow)
+	}
+	return window, nil
+}
+
+// DuplicateWindowDuration returns parsed duplicate window, zero means only exact duplicates
+func (c *Config) DuplicateWindowDuration() (time.Duration, error) {
+	if c.DuplicateWindow == "" {
+		return 0, nil
+	}
+	window, err := time.ParseDuration(c.DuplicateWindow)
+	if err != nil {
+		return 0, fmt.Errorf("DuplicateWindowDuration: %w", err)
+	}
+	if window < 0 {
+		return 0, fmt.Errorf("DuplicateWindowDuration: window must not be negative: %s", c.DuplicateWindow)
+	}
+	return window, nil
+}
+
+// FiringRangesOnLap returns numbers of firing ranges visited on the lap (starting from 1).
+// Ranges are visited in order and spread evenly over the laps
+func (c *Config) FiringRangesOnLap(lap int) []int {
+	var ranges []int
+	for r := 1; r <= c.FiringLines; r++ {
+		if (r-1)*c.Laps/c.FiringLines+1 == lap {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
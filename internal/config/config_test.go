@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestNew tests that the same config is read from every supported format
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"json", "config.json", `{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "firingLines": 2, "start": "10:00:00.000",
+"startDelta": "00:01:30", "log": {"outputs": ["stdout"]}, "sources": {"gate": {"offset": "2s"}}}`},
+		{"yaml", "config.yaml", `laps: 2
+lapLen: 3500
+penaltyLen: 150
+firingLines: 2
+start: 10:00:00.000
+startDelta: 00:01:30
+log:
+  outputs: [stdout]
+sources:
+  gate:
+    offset: 2s
+`},
+		{"yml", "config.yml", `{laps: 2, lapLen: 3500, penaltyLen: 150, firingLines: 2, start: "10:00:00.000",
+startDelta: "00:01:30", log: {outputs: [stdout]}, sources: {gate: {offset: 2s}}}`},
+		{"toml", "config.toml", `laps = 2
+lapLen = 3500
+penaltyLen = 150
+firingLines = 2
+start = "10:00:00.000"
+startDelta = "00:01:30"
+
+[log]
+outputs = ["stdout"]
+
+[sources.gate]
+offset = "2s"
+`},
+	}
+	expected := &Config{
+		Laps: 2, LapLen: 3500, PenaltyLen: 150, FiringLines: 2, Start: "10:00:00.000", StartDelta: "00:01:30",
+		Locale: "en", Log: LogConfig{Level: "info", Format: "console", Outputs: []string{"stdout"}},
+		Sources: map[string]SourceConfig{"gate": {Offset: "2s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := New(writeConfig(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cfg, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, cfg)
+			}
+		})
+	}
+
+	if _, err := New(writeConfig(t, "config.ini", "laps=2")); err == nil {
+		t.Errorf("Expected error for unknown format")
+	}
+}
+
+// TestLoad tests that environment variables override the file and key=value overrides take precedence over both
+func TestLoad(t *testing.T) {
+	filename := writeConfig(t, "config.json", `{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "firingLines": 2,
+"start": "10:00:00.000", "startDelta": "00:01:30", "log": {"level": "warn"}}`)
+	tests := []struct {
+		name      string
+		env       map[string]string
+		overrides []string
+		check     func(cfg *Config) bool
+		wantErr   bool
+	}{
+		{
+			name: "environment",
+			env:  map[string]string{"TELECOMTASK_LAP_LEN": "4000", "TELECOMTASK_LOG_LEVEL": "debug", "TELECOMTASK_LATE_START_ACTION": "drawn"},
+			check: func(cfg *Config) bool {
+				return cfg.LapLen == 4000 && cfg.Log.Level == "debug" && cfg.LateStart.Action == StartDrawn
+			},
+		},
+		{
+			name:      "overrides take precedence",
+			env:       map[string]string{"TELECOMTASK_LAPS": "3"},
+			overrides: []string{"laps=4", "log.outputs=stdout, race.log", "sources.gate.offset=-1s"},
+			check: func(cfg *Config) bool {
+				return cfg.Laps == 4 && reflect.DeepEqual(cfg.Log.Outputs, []string{"stdout", "race.log"}) &&
+					cfg.Sources["gate"].Offset == "-1s" && cfg.Log.Level == "warn"
+			},
+		},
+		{name: "unknown field", overrides: []string{"lapz=3"}, wantErr: true},
+		{name: "not key=value", overrides: []string{"laps"}, wantErr: true},
+		{name: "not integer", env: map[string]string{"TELECOMTASK_LAPS": "two"}, wantErr: true},
+		{name: "invalid result", overrides: []string{"laps=0"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			cfg, err := Load(filename, tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && !tt.check(cfg) {
+				t.Errorf("Unexpected config: %+v", cfg)
+			}
+		})
+	}
+}
+
+// TestEnvName tests names of environment variables of config fields
+func TestEnvName(t *testing.T) {
+	tests := map[string][]string{
+		"LAPS":               {"laps"},
+		"LAP_LEN":            {"lapLen"},
+		"LOG_OUTPUTS":        {"log", "outputs"},
+		"LATE_START_PENALTY": {"lateStart", "penalty"},
+	}
+	for expected, path := range tests {
+		if name := envName(path); name != expected {
+			t.Errorf("envName(%v): expected %s, got %s", path, expected, name)
+		}
+	}
+}
+
+// writeConfig writes config file to a temporary directory and returns its path
+func writeConfig(t *testing.T, name, content string) string {
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts names of environment variables overriding config fields. The rest of the name is the path
+// of the field in upper snake case, e.g. TELECOMTASK_LAPS, TELECOMTASK_LAP_LEN or TELECOMTASK_LOG_LEVEL
+const EnvPrefix = "TELECOMTASK_"
+
+// decoders decode config files by the file extension, all formats use the same field names as JSON
+var decoders = map[string]func([]byte, any) error{
+	".json": json.Unmarshal,
+	".yaml": yaml.Unmarshal,
+	".yml":  yaml.Unmarshal,
+	".toml": toml.Unmarshal,
+}
+
+// field is a config field which can be overridden: path of JSON names, "*" stands for any key of a map
+type field struct {
+	path []string
+	kind reflect.Kind
+}
+
+// fields lists all overridable fields of the config
+var fields = listFields(reflect.TypeOf(Config{}), nil)
+
+// listFields lists fields of the struct type with JSON names, nested structs and maps of structs are listed
+// field by field
+func listFields(t reflect.Type, prefix []string) []field {
+	var list []field
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		path := append(append([]string{}, prefix...), name)
+		switch ft := t.Field(i).Type; {
+		case ft.Kind() == reflect.Struct:
+			list = append(list, listFields(ft, path)...)
+		case ft.Kind() == reflect.Map && ft.Elem().Kind() == reflect.Struct:
+			list = append(list, listFields(ft.Elem(), append(path, "*"))...)
+		default:
+			list = append(list, field{path: path, kind: ft.Kind()})
+		}
+	}
+	return list
+}
+
+// readFields reads config file into a tree of fields by the file format
+func readFields(filename string) (map[string]any, error) {
+	decode, ok := decoders[strings.ToLower(filepath.Ext(filename))]
+	if !ok {
+		return nil, fmt.Errorf("readFields: unknown config format %s, expected .json, .yaml, .yml or .toml", filepath.Ext(filename))
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("readFields: error reading file: %w", err)
+	}
+	var tree map[string]any
+	if err = decode(data, &tree); err != nil {
+		return nil, fmt.Errorf("readFields: error decoding file: %w", err)
+	}
+	if tree == nil {
+		tree = make(map[string]any)
+	}
+	return tree, nil
+}
+
+// overrideFromEnv sets fields which have environment variables, fields of maps can be overridden only by key=value
+func overrideFromEnv(tree map[string]any, lookup func(string) (string, bool)) error {
+	for _, f := range fields {
+		if slices.Contains(f.path, "*") {
+			continue
+		}
+		name := EnvPrefix + envName(f.path)
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if err := set(tree, strings.Join(f.path, "."), value); err != nil {
+			return fmt.Errorf("overrideFromEnv: %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// envName converts path of JSON names to upper snake case: lateStart.penalty becomes LATE_START_PENALTY
+func envName(path []string) string {
+	var sb strings.Builder
+	for i, name := range path {
+		if i > 0 {
+			sb.WriteByte('_')
+		}
+		for j, r := range name {
+			if j > 0 && unicode.IsUpper(r) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return sb.String()
+}
+
+// set overrides the field by dotted path, e.g. "log.level" or "sources.gate.offset". The value is converted
+// by the field type: integers are parsed and lists are separated by commas
+func set(tree map[string]any, key, value string) error {
+	path := strings.Split(key, ".")
+	f, ok := lookupField(path)
+	if !ok {
+		return fmt.Errorf("set: unknown config field %s", key)
+	}
+	var typed any = value
+	switch f.kind {
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("set: field %s expects integer, got %s", key, value)
+		}
+		typed = n
+	case reflect.Slice:
+		var list []any
+		for _, item := range strings.Split(value, ",") {
+			list = append(list, strings.TrimSpace(item))
+		}
+		typed = list
+	}
+
+	node := tree
+	for _, name := range path[:len(path)-1] {
+		child, ok := node[name].(map[string]any)
+		if !ok {
+			if _, exists := node[name]; exists {
+				return fmt.Errorf("set: field %s is not an object in the config file", name)
+			}
+			child = make(map[string]any)
+			node[name] = child
+		}
+		node = child
+	}
+	node[path[len(path)-1]] = typed
+	return nil
+}
+
+// lookupField finds the overridable field by path, any name matches "*"
+func lookupField(path []string) (field, bool) {
+	for _, f := range fields {
+		if len(f.path) != len(path) {
+			continue
+		}
+		match := true
+		for i := range path {
+			if f.path[i] != path[i] && f.path[i] != "*" {
+				match = false
+				break
+			}
+		}
+		if match {
+			return f, true
+		}
+	}
+	return field{}, false
+}
@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/process"
+	"TelecomTask/internal/timestamp"
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Params describes the generated race
+type Params struct {
+	Competitors   int
+	Seed          int64
+	SpeedMean     float64 // mean ski speed, m/s
+	SpeedStdDev   float64 // standard deviation of ski speed between competitors, m/s
+	PenaltySpeed  float64 // mean speed on penalty laps, m/s
+	Accuracy      float64 // probability of hitting a target, 0..1
+	DNFRate       float64 // probability of not finishing the race, 0..1
+	LateStartRate float64 // probability of starting later than allowed, 0..1
+}
+
+// DefaultParams returns parameters of an average race
+func DefaultParams() Params {
+	return Params{
+		Competitors:   10,
+		Seed:          1,
+		SpeedMean:     4.5,
+		SpeedStdDev:   0.3,
+		PenaltySpeed:  3,
+		Accuracy:      0.85,
+		DNFRate:       0.05,
+		LateStartRate: 0.05,
+	}
+}
+
+// validate checks that params describe possible race
+func (p Params) validate() error {
+	if p.Competitors <= 0 {
+		return fmt.Errorf("number of competitors must be positive")
+	}
+	if p.SpeedMean <= 0 || p.PenaltySpeed <= 0 || p.SpeedStdDev < 0 {
+		return fmt.Errorf("speeds must be positive")
+	}
+	for _, rate := range []float64{p.Accuracy, p.DNFRate, p.LateStartRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("rates must be between 0 and 1")
+		}
+	}
+	return nil
+}
+
+// timedEvent is generated event with its exact time used for sorting
+type timedEvent struct {
+	at    time.Time
+	event process.Event
+}
+
+// race accumulates events of the generated race
+type race struct {
+	cfg    *config.Config
+	params Params
+	rnd    *rand.Rand
+	events []timedEvent
+	// dated tells to write times with date, it is set when the config start has date
+	dated bool
+}
+
+// add appends event of the competitor at given time
+func (r *race) add(at time.Time, eventID, competitorID int, extraParams ...string) {
+	at = at.Round(time.Millisecond)
+	r.events = append(r.events, timedEvent{
+		at: at,
+		event: process.Event{
+			Time:         timestamp.Format(at, r.dated),
+			EventID:      eventID,
+			CompetitorID: competitorID,
+			ExtraParams:  extraParams,
+		},
+	})
+}
+
+// between returns random duration in [from, to)
+func (r *race) between(from, to time.Duration) time.Duration {
+	return from + time.Duration(r.rnd.Int63n(int64(to-from)))
+}
+
+// ski returns time needed to pass distance with given speed
+func ski(distance, speed float64) time.Duration {
+	return time.Duration(distance / speed * float64(time.Second))
+}
+
+// Generate produces events of a race with given config, the same seed gives the same events
+func Generate(cfg *config.Config, params Params) ([]process.Event, error) {
+	if err := params.validate(); err != nil {
+		return nil, fmt.Errorf("Generate: invalid params: %w", err)
+	}
+	start, dated, err := timestamp.Parse(cfg.Start)
+	if err != nil {
+		return nil, fmt.Errorf("Generate: error parsing start: %w", err)
+	}
+	startDelta, err := cfg.StartDeltaDuration()
+	if err != nil {
+		return nil, fmt.Errorf("Generate: %w", err)
+	}
+	r := &race{cfg: cfg, params: params, rnd: rand.New(rand.NewSource(params.Seed)), dated: dated}
+
+	order := r.rnd.Perm(params.Competitors)
+	for i := 0; i < params.Competitors; i++ {
+		r.add(start.Add(-time.Hour+r.between(0, 30*time.Minute)), process.EventRegistered, i+1)
+	}
+	for i, idx := range order {
+		id := idx + 1
+		scheduled := start.Add(time.Duration(i) * startDelta)
+		r.add(scheduled.Add(-5*time.Minute), process.EventStartTimeDrawn, id, timestamp.Format(scheduled, dated))
+		r.competitor(id, scheduled, startDelta)
+	}
+
+	sort.SliceStable(r.events, func(i, j int) bool {
+		return r.events[i].at.Before(r.events[j].at)
+	})
+	events := make([]process.Event, len(r.events))
+	for i, e := range r.events {
+		events[i] = e.event
+	}
+	return events, nil
+}
+
+// competitor generates events of a single competitor from the start line to the finish
+func (r *race) competitor(id int, scheduled time.Time, startDelta time.Duration) {
+	speed := math.Max(1, r.rnd.NormFloat64()*r.params.SpeedStdDev+r.params.SpeedMean)
+	r.add(scheduled.Add(-r.between(10*time.Second, 30*time.Second)), process.EventOnStartLine, id)
+	now := scheduled.Add(r.between(0, 2*time.Second))
+	if r.rnd.Float64() < r.params.LateStartRate {
+		now = scheduled.Add(startDelta + r.between(time.Second, time.Minute))
+	}
+	r.add(now, process.EventStarted, id)
+
+	dnf := r.rnd.Float64() < r.params.DNFRate
+	dnfLap := r.rnd.Intn(r.cfg.Laps) + 1
+	for lap := 1; lap <= r.cfg.Laps; lap++ {
+		ranges := r.cfg.FiringRangesOnLap(lap)
+		segment := float64(r.cfg.LapLen) / float64(len(ranges)+1)
+		lapSpeed := speed * (1 + 0.03*r.rnd.NormFloat64())
+		for _, firingRange := range ranges {
+			now = now.Add(ski(segment, lapSpeed))
+			now = r.shooting(id, firingRange, now)
+		}
+		now = now.Add(ski(segment, lapSpeed))
+		if dnf && lap == dnfLap {
+			r.add(now.Add(-r.between(0, ski(segment, lapSpeed))), process.EventCannotContinue, id, "Lost", "in", "the", "forest")
+			return
+		}
+		r.add(now, process.EventLapEnded, id)
+	}
+}
+
+// shooting generates firing range visit with penalty laps and returns the time the competitor is back on course
+func (r *race) shooting(id, firingRange int, now time.Time) time.Time {
+	r.add(now, process.EventOnFiringRange, id, strconv.Itoa(firingRange))
+	misses := 0
+	for target := 1; target <= process.TargetsPerRange; target++ {
+		now = now.Add(r.between(300*time.Millisecond, 2*time.Second))
+		if r.rnd.Float64() < r.params.Accuracy {
+			r.add(now, process.EventTargetHit, id, strconv.Itoa(target))
+		} else {
+			misses++
+		}
+	}
+	now = now.Add(r.between(time.Second, 4*time.Second))
+	r.add(now, process.EventLeftFiringRange, id)
+	if misses == 0 {
+		return now
+	}
+	now = now.Add(r.between(3*time.Second, 10*time.Second))
+	r.add(now, process.EventEnteredPenalty, id)
+	speed := r.params.PenaltySpeed * (1 + 0.05*r.rnd.NormFloat64())
+	now = now.Add(ski(float64(r.cfg.PenaltyLen*misses), math.Max(1, speed)))
+	r.add(now, process.EventLeftPenalty, id)
+	return now
+}
+
+// Write writes events in the format of events file
+func Write(w io.Writer, events []process.Event) error {
+	buffered := bufio.NewWriter(w)
+	for _, event := range events {
+		if _, err := fmt.Fprintln(buffered, event.String()); err != nil {
+			return fmt.Errorf("Write: %w", err)
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("Write: %w", err)
+	}
+	return nil
+}
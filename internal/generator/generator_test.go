@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/process"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testConfig = &config.Config{
+	Laps:        3,
+	LapLen:      3000,
+	PenaltyLen:  150,
+	FiringLines: 2,
+	Start:       "10:00:00.000",
+	StartDelta:  "00:00:30",
+}
+
+// TestGenerateReproducible tests that the same seed gives the same race
+func TestGenerateReproducible(t *testing.T) {
+	params := DefaultParams()
+	params.Competitors = 20
+	first, err := Generate(testConfig, params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := Generate(testConfig, params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Error("Expected the same events for the same seed")
+	}
+	params.Seed++
+	third, err := Generate(testConfig, params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reflect.DeepEqual(first, third) {
+		t.Error("Expected different events for different seeds")
+	}
+}
+
+// TestGenerateInvalidParams tests validation of params
+func TestGenerateInvalidParams(t *testing.T) {
+	params := DefaultParams()
+	params.Accuracy = 1.5
+	if _, err := Generate(testConfig, params); err == nil {
+		t.Error("Expected error for accuracy above 1")
+	}
+	params = DefaultParams()
+	params.Competitors = 0
+	if _, err := Generate(testConfig, params); err == nil {
+		t.Error("Expected error for zero competitors")
+	}
+}
+
+// TestGenerateConsistent tests that every competitor's events follow the course rules
+func TestGenerateConsistent(t *testing.T) {
+	params := DefaultParams()
+	params.Competitors = 50
+	params.DNFRate = 0.2
+	events, err := Generate(testConfig, params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var previous time.Time
+	laps := make(map[int]int)
+	ranges := make(map[int][]string)
+	notFinished := make(map[int]bool)
+	for i, event := range events {
+		eventTime, err := time.Parse("15:04:05.000", event.Time)
+		if err != nil {
+			t.Fatalf("Invalid event time %s", event.Time)
+		}
+		if i > 0 && eventTime.Before(previous) {
+			t.Fatalf("Events are not sorted at %s", event)
+		}
+		previous = eventTime
+		if notFinished[event.CompetitorID] {
+			t.Errorf("Event after competitor can't continue: %s", event)
+		}
+		switch event.EventID {
+		case 5:
+			ranges[event.CompetitorID] = append(ranges[event.CompetitorID], event.ExtraParams[0])
+		case 10:
+			laps[event.CompetitorID]++
+		case 11:
+			notFinished[event.CompetitorID] = true
+		}
+	}
+
+	for id := 1; id <= params.Competitors; id++ {
+		if notFinished[id] {
+			continue
+		}
+		if laps[id] != testConfig.Laps {
+			t.Errorf("Competitor %d: expected %d laps, got %d", id, testConfig.Laps, laps[id])
+		}
+		if !reflect.DeepEqual(ranges[id], []string{"1", "2"}) {
+			t.Errorf("Competitor %d: expected ranges [1 2], got %v", id, ranges[id])
+		}
+	}
+	if len(notFinished) == 0 {
+		t.Error("Expected some competitors not to finish")
+	}
+}
+
+// TestWrite tests that written events can be loaded back
+func TestWrite(t *testing.T) {
+	events, err := Generate(testConfig, DefaultParams())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tmpfile, err := os.CreateTemp("", "generated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(name string) {
+		err = os.Remove(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}(tmpfile.Name())
+	if err := Write(tmpfile, events); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := process.LoadEvents(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(loaded) != len(events) {
+		t.Fatalf("Expected %d events, got %d", len(events), len(loaded))
+	}
+	for i := range events {
+		if loaded[i].String() != events[i].String() {
+			t.Errorf("Event %d: expected %s, got %s", i, events[i], loaded[i])
+		}
+	}
+}
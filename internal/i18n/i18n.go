@@ -0,0 +1,236 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the locale used when none is configured
+const Default = "en"
+
+// Catalog contains messages and formatting rules of a single locale
+type Catalog struct {
+	Locale string
+	// Events are message templates by event id, missing templates fall back to the event catalog
+	Events map[int]string
+	// Statuses are names of competitor statuses shown in the report
+	Statuses map[string]string
+	// Reasons are descriptions of rejected finish reasons and outcomes of starts out of time shown in the report
+	Reasons map[string]string
+	// ReportHeader is the first line of the resulting table
+	ReportHeader string
+	// SeasonHeader is the first line of the season standings table
+	SeasonHeader string
+	// ExplainLap, ExplainPenalty, ExplainHitsShots and ExplainJury are titles of the explanation sections,
+	// the explanation lists events behind every figure of the report line
+	ExplainLap       string
+	ExplainPenalty   string
+	ExplainHitsShots string
+	ExplainJury      string
+	// BoardClock, BoardColumns, BoardRange, BoardPenalty and BoardFeed are texts of the live scoreboard:
+	// race clock title, standings columns, position on a firing range and on penalty laps, recent events title
+	BoardClock   string
+	BoardColumns [7]string
+	BoardRange   string
+	BoardPenalty string
+	BoardFeed    string
+	// LiveHeader is the first line of the live standings table, LiveLap and LiveRange describe checkpoints:
+	// the lap end and arrival on the firing range of the lap
+	LiveHeader string
+	LiveLap    string
+	LiveRange  string
+	// DecimalSeparator separates fractional part of numbers and seconds
+	DecimalSeparator string
+}
+
+var catalogs = map[string]*Catalog{
+	"en": {
+		Locale: "en",
+		Events: map[int]string{},
+		Statuses: map[string]string{
+			"Registered":   "Registered",
+			"Started":      "Started",
+			"NotStarted":   "NotStarted",
+			"NotFinished":  "NotFinished",
+			"Finished":     "Finished",
+			"Disqualified": "Disqualified",
+		},
+		Reasons: map[string]string{
+			"ranges_missing":           "firing range missed",
+			"penalty_not_served":       "penalty laps not served",
+			"lap_in_penalty":           "lap ended on penalty laps",
+			"negative_duration":        "negative duration",
+			"penalty_not_entered":      "penalty laps left without entering",
+			"late_start_disqualified":  "late start, disqualified",
+			"late_start_penalty":       "late start, time penalty",
+			"late_start_drawn":         "late start, timed from the drawn start",
+			"early_start_disqualified": "early start, disqualified",
+			"early_start_penalty":      "early start, time penalty",
+			"early_start_drawn":        "early start, timed from the drawn start",
+			"did_not_start":            "did not start",
+		},
+		ReportHeader:     "[Total time] Competitor [{Lap time Lap speed}...] Penalty time Penalty speed Hits/Shots Scheduled start/Actual start",
+		SeasonHeader:     "Rank [Points] Competitor [{Place Points}...]",
+		ExplainLap:       "Lap %d: %s",
+		ExplainPenalty:   "Penalty laps %d: %s",
+		ExplainHitsShots: "Hits/Shots: %s",
+		ExplainJury:      "Adjusted by the jury: %s",
+		BoardClock:       "Race clock",
+		BoardColumns:     [7]string{"Rank", "Competitor", "Laps", "Position", "Penalty", "Gap", "Predicted finish"},
+		BoardRange:       "firing range %d",
+		BoardPenalty:     "penalty laps",
+		BoardFeed:        "Recent events",
+		LiveHeader:       "Rank Competitor {Checkpoint} [Time] Gap Projected time",
+		LiveLap:          "lap %d",
+		LiveRange:        "firing range %d, lap %d",
+		DecimalSeparator: ".",
+	},
+	"ru": {
+		Locale: "ru",
+		Events: map[int]string{
+			1:  "Участник({competitor}) зарегистрирован",
+			2:  "Время старта участника({competitor}) определено жеребьевкой: {param}",
+			3:  "Участник({competitor}) на стартовой линии",
+			4:  "Участник({competitor}) стартовал",
+			5:  "Участник({competitor}) на огневом рубеже({param})",
+			6:  "Мишень({param}) поражена участником({competitor})",
+			7:  "Участник({competitor}) покинул огневой рубеж",
+			8:  "Участник({competitor}) вышел на штрафные круги",
+			9:  "Участник({competitor}) завершил штрафные круги",
+			10: "Участник({competitor}) завершил основной круг",
+			11: "Участник({competitor}) не может продолжить: {params}",
+			32: "Участник({competitor}) дисквалифицирован",
+			33: "Участник({competitor}) финишировал",
+			34: "Участник({competitor}) не вышел на старт",
+		},
+		Statuses: map[string]string{
+			"Registered":   "Зарегистрирован",
+			"Started":      "Стартовал",
+			"NotStarted":   "НеСтартовал",
+			"NotFinished":  "НеФинишировал",
+			"Finished":     "Финишировал",
+			"Disqualified": "Дисквалифицирован",
+		},
+		Reasons: map[string]string{
+			"ranges_missing":           "пропущен огневой рубеж",
+			"penalty_not_served":       "не пройдены штрафные круги",
+			"lap_in_penalty":           "круг завершен на штрафных кругах",
+			"negative_duration":        "отрицательная длительность",
+			"penalty_not_entered":      "уход со штрафных кругов без выхода на них",
+			"late_start_disqualified":  "опоздание на старт, дисквалификация",
+			"late_start_penalty":       "опоздание на старт, штраф по времени",
+			"late_start_drawn":         "опоздание на старт, время от старта по жеребьевке",
+			"early_start_disqualified": "ранний старт, дисквалификация",
+			"early_start_penalty":      "ранний старт, штраф по времени",
+			"early_start_drawn":        "ранний старт, время от старта по жеребьевке",
+			"did_not_start":            "не вышел на старт",
+		},
+		ReportHeader:     "[Общее время] Участник [{Время круга Скорость}...] Время штрафа Скорость на штрафе Попадания/Выстрелы Старт по жеребьевке/Фактический старт",
+		SeasonHeader:     "Место [Очки] Участник [{Место Очки}...]",
+		ExplainLap:       "Круг %d: %s",
+		ExplainPenalty:   "Штрафные круги %d: %s",
+		ExplainHitsShots: "Попадания/Выстрелы: %s",
+		ExplainJury:      "Изменено жюри: %s",
+		BoardClock:       "Время гонки",
+		BoardColumns:     [7]string{"Место", "Участник", "Круги", "Положение", "Штраф", "Отставание", "Прогноз финиша"},
+		BoardRange:       "огневой рубеж %d",
+		BoardPenalty:     "штрафные круги",
+		BoardFeed:        "Последние события",
+		LiveHeader:       "Место Участник {Отметка} [Время] Отставание Прогноз",
+		LiveLap:          "круг %d",
+		LiveRange:        "огневой рубеж %d, круг %d",
+		DecimalSeparator: ",",
+	},
+}
+
+// Get returns catalog of the locale, empty locale means Default
+func Get(locale string) (*Catalog, error) {
+	if locale == "" {
+		locale = Default
+	}
+	catalog, ok := catalogs[locale]
+	if !ok {
+		return nil, fmt.Errorf("Get: unknown locale %s, supported: %s", locale, strings.Join(Locales(), ", "))
+	}
+	return catalog, nil
+}
+
+// Locales returns names of all supported locales
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// EventTemplate returns message template of the event or fallback if the locale does not translate it
+func (c *Catalog) EventTemplate(id int, fallback string) string {
+	if template, ok := c.Events[id]; ok {
+		return template
+	}
+	return fallback
+}
+
+// Status returns localized name of competitor status
+func (c *Catalog) Status(status string) string {
+	if name, ok := c.Statuses[status]; ok {
+		return name
+	}
+	return status
+}
+
+// Reason returns localized description of the rejected finish reason
+func (c *Catalog) Reason(reason string) string {
+	if description, ok := c.Reasons[reason]; ok {
+		return description
+	}
+	return reason
+}
+
+// ReasonKey returns rejected finish reason by its localized description, it is the reverse of Reason
+func (c *Catalog) ReasonKey(description string) string {
+	for reason, localized := range c.Reasons {
+		if localized == description {
+			return reason
+		}
+	}
+	return description
+}
+
+// StatusKey returns competitor status by its localized name, it is the reverse of Status
+func (c *Catalog) StatusKey(name string) string {
+	for status, localized := range c.Statuses {
+		if localized == name {
+			return status
+		}
+	}
+	return name
+}
+
+// ParseNumber parses number formatted by Number
+func (c *Catalog) ParseNumber(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.Replace(s, c.DecimalSeparator, ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("ParseNumber: %w", err)
+	}
+	return v, nil
+}
+
+// Number formats number with given precision and the locale decimal separator
+func (c *Catalog) Number(v float64, precision int) string {
+	return strings.Replace(strconv.FormatFloat(v, 'f', precision, 64), ".", c.DecimalSeparator, 1)
+}
+
+// Duration converts duration in HH:MM:SS.sss format to the locale decimal separator
+func (c *Catalog) Duration(formatted string) string {
+	return strings.Replace(formatted, ".", c.DecimalSeparator, 1)
+}
+
+// ParseDuration converts localized duration back to HH:MM:SS.sss format, it is the reverse of Duration
+func (c *Catalog) ParseDuration(localized string) string {
+	return strings.Replace(localized, c.DecimalSeparator, ".", 1)
+}
@@ -0,0 +1,65 @@
+package i18n
+
+import "testing"
+
+// TestGet tests lookup of catalogs
+func TestGet(t *testing.T) {
+	catalog, err := Get("")
+	if err != nil || catalog.Locale != Default {
+		t.Errorf("Expected default catalog for empty locale, got %v, %v", catalog, err)
+	}
+	if _, err := Get("de"); err == nil {
+		t.Error("Expected error for unknown locale")
+	}
+	for _, locale := range Locales() {
+		if _, err := Get(locale); err != nil {
+			t.Errorf("Unexpected error for locale %s: %v", locale, err)
+		}
+	}
+}
+
+// TestFormatting tests locale-aware formatting of numbers, durations and statuses
+func TestFormatting(t *testing.T) {
+	en, _ := Get("en")
+	ru, _ := Get("ru")
+	tests := []struct {
+		catalog  *Catalog
+		actual   string
+		expected string
+	}{
+		{en, en.Number(4.5908, 3), "4.591"},
+		{ru, ru.Number(4.5908, 3), "4,591"},
+		{en, en.Duration("00:12:42.386"), "00:12:42.386"},
+		{ru, ru.Duration("00:12:42.386"), "00:12:42,386"},
+		{en, en.Status("NotFinished"), "NotFinished"},
+		{en, en.Status("Finished"), "Finished"},
+		{ru, ru.Status("NotFinished"), "НеФинишировал"},
+		{en, en.EventTemplate(1, "fallback"), "fallback"},
+		{ru, ru.EventTemplate(1, "fallback"), "Участник({competitor}) зарегистрирован"},
+		{ru, ru.StatusKey("НеФинишировал"), "NotFinished"},
+		{en, en.Reason("ranges_missing"), "firing range missed"},
+		{ru, ru.ReasonKey(ru.Reason("ranges_missing")), "ranges_missing"},
+		{ru, ru.ParseDuration("00:12:42,386"), "00:12:42.386"},
+	}
+	for _, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("Locale %s: expected %q, got %q", test.catalog.Locale, test.expected, test.actual)
+		}
+	}
+}
+
+// TestStatuses tests that every locale names the same competitor statuses
+func TestStatuses(t *testing.T) {
+	en, _ := Get(Default)
+	for _, locale := range Locales() {
+		catalog, _ := Get(locale)
+		if len(catalog.Statuses) != len(en.Statuses) {
+			t.Errorf("Locale %s: expected %d statuses, got %d", locale, len(en.Statuses), len(catalog.Statuses))
+		}
+		for status := range en.Statuses {
+			if _, ok := catalog.Statuses[status]; !ok {
+				t.Errorf("Locale %s: status %s is not translated", locale, status)
+			}
+		}
+	}
+}
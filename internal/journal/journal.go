@@ -0,0 +1,126 @@
+package journal
+
+import (
+	"TelecomTask/internal/process"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Journal is a durable write-ahead log of accepted events. Every event is appended to the journal
+// before it is processed, so the state of the race can be rebuilt after a crash by processing the journal again.
+// A record is the line number of the event in the events file followed by the event itself
+type Journal struct {
+	file   *os.File
+	events []process.Event
+}
+
+// Open opens journal file or creates it if it does not exist. Incomplete last line left by a crash
+// during writing is dropped, since its event was never processed
+func Open(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("Open: error opening journal: %w", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("Open: error reading journal: %w", err)
+	}
+
+	complete := bytes.LastIndexByte(data, '\n') + 1
+	var events []process.Event
+	for i, line := range bytes.Split(data[:complete], []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		event, err := parseRecord(string(line))
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("Open: journal is corrupted at line %d: %w", i+1, err)
+		}
+		events = append(events, event)
+	}
+	if complete < len(data) {
+		if err = file.Truncate(int64(complete)); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("Open: error dropping incomplete record: %w", err)
+		}
+	}
+	if _, err = file.Seek(int64(complete), io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("Open: %w", err)
+	}
+	return &Journal{file: file, events: events}, nil
+}
+
+// parseRecord parses journal record into the event with its line number
+func parseRecord(record string) (process.Event, error) {
+	number, text, ok := strings.Cut(record, " ")
+	if !ok {
+		return process.Event{}, fmt.Errorf("parseRecord: no line number in record")
+	}
+	lineNumber, err := strconv.Atoi(number)
+	if err != nil {
+		return process.Event{}, fmt.Errorf("parseRecord: error in line number: %w", err)
+	}
+	event, err := process.ParseEvent(text)
+	if err != nil {
+		return process.Event{}, fmt.Errorf("parseRecord: %w", err)
+	}
+	event.Line = lineNumber
+	return event, nil
+}
+
+// Events returns events recorded in the journal
+func (j *Journal) Events() []process.Event {
+	return j.events
+}
+
+// Append durably records the event, it returns only after the record reaches the disk
+func (j *Journal) Append(event process.Event) error {
+	if _, err := fmt.Fprintf(j.file, "%d %s\n", event.Line, event); err != nil {
+		return fmt.Errorf("Append: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("Append: %w", err)
+	}
+	j.events = append(j.events, event)
+	return nil
+}
+
+// Truncate drops all recorded events, it is used when the race is processed again from the beginning
+func (j *Journal) Truncate() error {
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("Truncate: %w", err)
+	}
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("Truncate: %w", err)
+	}
+	j.events = nil
+	return nil
+}
+
+// Close closes journal file
+func (j *Journal) Close() error {
+	if err := j.file.Close(); err != nil {
+		return fmt.Errorf("Close: %w", err)
+	}
+	return nil
+}
+
+// Remaining checks that recorded events are the beginning of input and returns input events which are not recorded yet
+func Remaining(recorded, input []process.Event) ([]process.Event, error) {
+	if len(recorded) > len(input) {
+		return nil, fmt.Errorf("Remaining: journal has %d events, input has only %d", len(recorded), len(input))
+	}
+	for i, event := range recorded {
+		if event.String() != input[i].String() {
+			return nil, fmt.Errorf("Remaining: journal does not match input at event %d: %s", i+1, event)
+		}
+	}
+	return input[len(recorded):], nil
+}
@@ -0,0 +1,120 @@
+package journal
+
+import (
+	"TelecomTask/internal/process"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testEvents = []process.Event{
+	{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, Line: 1},
+	{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: []string{"09:30:00.000"}, Line: 2},
+	{Time: "09:29:45.000", EventID: 3, CompetitorID: 1, Line: 4},
+}
+
+// TestAppendAndReopen tests that appended events are restored with their line numbers after reopening the journal
+func TestAppendAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	j, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(j.Events()) != 0 {
+		t.Errorf("Expected empty journal, got %d events", len(j.Events()))
+	}
+	for _, event := range testEvents[:2] {
+		if err := j.Append(event); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err = Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(j.Events()) != 2 || j.Events()[1].String() != testEvents[1].String() {
+		t.Fatalf("Unexpected restored events: %v", j.Events())
+	}
+	if err := j.Append(testEvents[2]); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err = Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer func() {
+		_ = j.Close()
+	}()
+	if len(j.Events()) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(j.Events()))
+	}
+	for i, event := range j.Events() {
+		if event.Line != testEvents[i].Line {
+			t.Errorf("Event %d: expected line %d, got %d", i+1, testEvents[i].Line, event.Line)
+		}
+	}
+}
+
+// TestOpenIncompleteRecord tests that a record torn by a crash is dropped
+func TestOpenIncompleteRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	if err := os.WriteFile(path, []byte("1 [09:05:59.867] 1 1\n2 [09:15:00.841] 2 1 09:3"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	j, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(j.Events()) != 1 {
+		t.Fatalf("Expected 1 complete event, got %d", len(j.Events()))
+	}
+	if err := j.Append(testEvents[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "1 [09:05:59.867] 1 1\n2 [09:15:00.841] 2 1 09:30:00.000\n" {
+		t.Errorf("Unexpected journal content: %q", data)
+	}
+}
+
+// TestOpenCorrupted tests that a broken record in the middle of the journal is reported
+func TestOpenCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	if err := os.WriteFile(path, []byte("1 [09:05:59.867] 1 1\n2 garbage\n4 [09:29:45.000] 3 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Error("Expected error for corrupted journal")
+	}
+}
+
+// TestRemaining tests matching of journal and input events
+func TestRemaining(t *testing.T) {
+	remaining, err := Remaining(testEvents[:2], testEvents)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].String() != testEvents[2].String() {
+		t.Errorf("Unexpected remaining events: %v", remaining)
+	}
+	if _, err := Remaining(testEvents, testEvents[:2]); err == nil {
+		t.Error("Expected error for journal longer than input")
+	}
+	if _, err := Remaining(testEvents[1:], testEvents); err == nil {
+		t.Error("Expected error for journal of another race")
+	}
+}
@@ -0,0 +1,135 @@
+package jury
+
+import (
+	"TelecomTask/internal/process"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Actions of the jury decisions
+const (
+	// ActionTimeAdjustment adds time to the total time of the competitor, negative time is deducted
+	ActionTimeAdjustment = "time_adjustment"
+	// ActionReinstate cancels disqualification or rejected finish of the competitor who completed all laps
+	ActionReinstate = "reinstate"
+	// ActionDisqualify disqualifies the competitor, for example after a protest
+	ActionDisqualify = "disqualify"
+)
+
+// Decision is a manual decision of the officials applied on top of the computed race state
+type Decision struct {
+	Competitor int    `json:"competitor"`
+	Action     string `json:"action"`
+	// Time is the time added by the time adjustment in Go duration format, e.g. "1m" or "-30s"
+	Time   string `json:"time,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Duration returns time of the time adjustment
+func (d Decision) Duration() (time.Duration, error) {
+	adjustment, err := time.ParseDuration(d.Time)
+	if err != nil {
+		return 0, fmt.Errorf("Duration: %w", err)
+	}
+	return adjustment, nil
+}
+
+// validate checks that the decision is complete
+func (d Decision) validate() error {
+	switch d.Action {
+	case ActionTimeAdjustment:
+		if _, err := d.Duration(); err != nil {
+			return fmt.Errorf("validate: invalid time of competitor %d: %w", d.Competitor, err)
+		}
+	case ActionReinstate, ActionDisqualify:
+	default:
+		return fmt.Errorf("validate: unknown action %q of competitor %d", d.Action, d.Competitor)
+	}
+	if d.Reason == "" {
+		return fmt.Errorf("validate: decision on competitor %d has no reason", d.Competitor)
+	}
+	return nil
+}
+
+// Load loads decisions from json file, decisions are applied in the order of the file
+func Load(filename string) ([]Decision, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Load: error reading file: %w", err)
+	}
+	var decisions []Decision
+	if err = json.Unmarshal(data, &decisions); err != nil {
+		return nil, fmt.Errorf("Load: error parsing json: %w", err)
+	}
+	for _, d := range decisions {
+		if err = d.validate(); err != nil {
+			return nil, fmt.Errorf("Load: %w", err)
+		}
+	}
+	return decisions, nil
+}
+
+// Apply applies decisions to the competitors of the race with given number of laps. Every decision is logged,
+// a decision which cannot be applied is logged as rejected and leaves the competitor as is
+func Apply(competitors map[int]*process.Competitor, laps int, decisions []Decision, logger *zap.Logger) {
+	for _, d := range decisions {
+		fields := []zap.Field{
+			zap.Int("competitor", d.Competitor),
+			zap.String("action", d.Action),
+			zap.String("reason", d.Reason),
+		}
+		if d.Time != "" {
+			fields = append(fields, zap.String("time", d.Time))
+		}
+		comp, ok := competitors[d.Competitor]
+		if !ok {
+			logger.Warn("Jury: decision rejected", append(fields, zap.Error(fmt.Errorf("unknown competitor")))...)
+			continue
+		}
+		previous := comp.Status
+		if err := apply(comp, laps, d); err != nil {
+			logger.Warn("Jury: decision rejected", append(fields, zap.Error(err))...)
+			continue
+		}
+		comp.Adjusted = true
+		logger.Info("Jury: decision applied", append(fields,
+			zap.String("previousStatus", previous), zap.String("status", comp.Status))...)
+	}
+}
+
+// apply changes the competitor according to the decision
+func apply(comp *process.Competitor, laps int, d Decision) error {
+	switch d.Action {
+	case ActionTimeAdjustment:
+		adjustment, err := d.Duration()
+		if err != nil {
+			return fmt.Errorf("apply: %w", err)
+		}
+		comp.TimeAdjustment += adjustment
+	case ActionReinstate:
+		if comp.Status == "Finished" {
+			return fmt.Errorf("apply: competitor is not disqualified")
+		}
+		if len(comp.LapTimes) != laps {
+			return fmt.Errorf("apply: competitor completed %d of %d laps", len(comp.LapTimes), laps)
+		}
+		comp.Status = "Finished"
+		comp.FinishRejection = ""
+		if comp.StartOutcome == process.StartLateDisqualified || comp.StartOutcome == process.StartEarlyDisqualified {
+			comp.StartOutcome = ""
+		}
+	case ActionDisqualify:
+		if comp.Status == "Disqualified" {
+			return fmt.Errorf("apply: competitor is already disqualified")
+		}
+		comp.Status = "Disqualified"
+		comp.FinishRejection = ""
+	default:
+		return fmt.Errorf("apply: unknown action %q", d.Action)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package jury
+
+import (
+	"TelecomTask/internal/process"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestLoad tests validation of the decisions file
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		isError bool
+	}{
+		{"valid", `[{"competitor": 1, "action": "time_adjustment", "time": "-30s", "reason": "timing error"}]`, false},
+		{"unknown action", `[{"competitor": 1, "action": "warn", "reason": "timing error"}]`, true},
+		{"invalid time", `[{"competitor": 1, "action": "time_adjustment", "time": "00:00:30", "reason": "timing error"}]`, true},
+		{"no reason", `[{"competitor": 1, "action": "disqualify"}]`, true},
+		{"invalid json", `{`, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "decisions.json")
+			if err := os.WriteFile(path, []byte(test.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			if (err != nil) != test.isError {
+				t.Errorf("Unexpected error state: %v", err)
+			}
+		})
+	}
+}
+
+// TestApply tests that applied decisions change the competitor and rejected decisions leave it as is
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		competitor process.Competitor
+		decision   Decision
+		status     string
+		adjustment time.Duration
+		applied    bool
+	}{
+		{"time adjustment", process.Competitor{ID: 1, Status: "Finished"},
+			Decision{Competitor: 1, Action: ActionTimeAdjustment, Time: "1m", Reason: "r"}, "Finished", time.Minute, true},
+		{"reinstate", process.Competitor{ID: 1, Status: "NotFinished", LapTimes: make([]time.Duration, 2), FinishRejection: process.ReasonRangesMissing},
+			Decision{Competitor: 1, Action: ActionReinstate, Reason: "r"}, "Finished", 0, true},
+		{"reinstate late start", process.Competitor{ID: 1, Status: "NotStarted", LapTimes: make([]time.Duration, 2), StartOutcome: process.StartLateDisqualified},
+			Decision{Competitor: 1, Action: ActionReinstate, Reason: "r"}, "Finished", 0, true},
+		{"reinstate early start", process.Competitor{ID: 1, Status: "NotStarted", LapTimes: make([]time.Duration, 2), StartOutcome: process.StartEarlyDisqualified},
+			Decision{Competitor: 1, Action: ActionReinstate, Reason: "r"}, "Finished", 0, true},
+		{"reinstate incomplete", process.Competitor{ID: 1, Status: "NotFinished", LapTimes: make([]time.Duration, 1)},
+			Decision{Competitor: 1, Action: ActionReinstate, Reason: "r"}, "NotFinished", 0, false},
+		{"reinstate finished", process.Competitor{ID: 1, Status: "Finished", LapTimes: make([]time.Duration, 2)},
+			Decision{Competitor: 1, Action: ActionReinstate, Reason: "r"}, "Finished", 0, false},
+		{"disqualify", process.Competitor{ID: 1, Status: "Finished"},
+			Decision{Competitor: 1, Action: ActionDisqualify, Reason: "r"}, "Disqualified", 0, true},
+		{"unknown competitor", process.Competitor{ID: 1, Status: "Finished"},
+			Decision{Competitor: 2, Action: ActionDisqualify, Reason: "r"}, "Finished", 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			comp := test.competitor
+			Apply(map[int]*process.Competitor{comp.ID: &comp}, 2, []Decision{test.decision}, zap.New(core))
+			if comp.Status != test.status || comp.TimeAdjustment != test.adjustment || comp.Adjusted != test.applied {
+				t.Errorf("Unexpected competitor: %+v", comp)
+			}
+			if comp.Status == "Finished" && comp.FinishRejection != "" {
+				t.Errorf("Expected rejection to be cleared, got %q", comp.FinishRejection)
+			}
+			if comp.Status == "Finished" && (comp.StartOutcome == process.StartLateDisqualified || comp.StartOutcome == process.StartEarlyDisqualified) {
+				t.Errorf("Expected start disqualification to be cleared, got %q", comp.StartOutcome)
+			}
+			message := "Jury: decision rejected"
+			if test.applied {
+				message = "Jury: decision applied"
+			}
+			if logs.FilterMessage(message).Len() != 1 {
+				t.Errorf("Expected %q in the log, got %v", message, logs.All())
+			}
+		})
+	}
+}
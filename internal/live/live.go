@@ -0,0 +1,186 @@
+package live
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/process"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Checkpoint is a point of the course where competitors are compared: arrival on a firing range or the lap end
+type Checkpoint struct {
+	// Lap is the lap of the checkpoint, starting from 1
+	Lap int
+	// Range is the firing range of the checkpoint, 0 for the lap end
+	Range int
+}
+
+// Standing is the position of a competitor at some moment of the race
+type Standing struct {
+	// Rank is the place among competitors in the race, 0 if the competitor is not in the race at the moment
+	Rank         int
+	CompetitorID int
+	// Status is the status of the competitor at the moment
+	Status string
+	// Checkpoints is the number of checkpoints passed, Checkpoint is the latest of them
+	Checkpoints int
+	Checkpoint  Checkpoint
+	// Elapsed is the time from the start to the latest checkpoint, the total time of the report for finished competitors
+	Elapsed time.Duration
+	// Gap is the time behind the leader at the latest checkpoint of the competitor
+	Gap time.Duration
+	// Projected is the predicted total time of the race for competitors on course, the total time for finished ones,
+	// 0 if it cannot be predicted yet
+	Projected time.Duration
+}
+
+// progress is the course of a competitor at the moment
+type progress struct {
+	comp *process.Competitor
+	// times are times from the start to the passed checkpoints
+	times  []time.Duration
+	lastCP Checkpoint
+}
+
+// Standings computes partial ranking at the moment from the events of the race processed up to it.
+// Finished competitors are ranked by the total time, competitors on course by the number of passed checkpoints
+// and the time at the latest of them, so that athletes are compared at the same checkpoint.
+// Competitors who have not started or are out of the race at the moment are listed last without rank
+func Standings(cfg *config.Config, events []process.Event, at time.Time) []Standing {
+	competitors := process.StateAt(cfg, events, at)
+	progresses := make([]progress, 0, len(competitors))
+	for _, comp := range competitors {
+		progresses = append(progresses, trace(comp))
+	}
+	sort.Slice(progresses, func(i, j int) bool {
+		a, b := progresses[i], progresses[j]
+		if ranked(a.comp.Status) != ranked(b.comp.Status) {
+			return ranked(a.comp.Status)
+		}
+		if !ranked(a.comp.Status) {
+			return a.comp.ID < b.comp.ID
+		}
+		if (a.comp.Status == "Finished") != (b.comp.Status == "Finished") {
+			return a.comp.Status == "Finished"
+		}
+		if a.comp.Status == "Finished" && process.TotalTime(a.comp) != process.TotalTime(b.comp) {
+			return process.TotalTime(a.comp) < process.TotalTime(b.comp)
+		}
+		if len(a.times) != len(b.times) {
+			return len(a.times) > len(b.times)
+		}
+		if len(a.times) > 0 && a.times[len(a.times)-1] != b.times[len(b.times)-1] {
+			return a.times[len(a.times)-1] < b.times[len(b.times)-1]
+		}
+		return a.comp.ID < b.comp.ID
+	})
+
+	standings := make([]Standing, len(progresses))
+	for i, p := range progresses {
+		s := Standing{CompetitorID: p.comp.ID, Status: p.comp.Status, Checkpoints: len(p.times), Checkpoint: p.lastCP}
+		if len(p.times) > 0 {
+			s.Elapsed = p.times[len(p.times)-1]
+		}
+		if ranked(p.comp.Status) {
+			s.Rank = i + 1
+			s.Projected = p.comp.Prediction.Total
+		}
+		if p.comp.Status == "Finished" {
+			s.Elapsed = process.TotalTime(p.comp)
+			s.Projected = s.Elapsed
+		}
+		standings[i] = s
+	}
+	if len(progresses) == 0 {
+		return standings
+	}
+	leader := progresses[0]
+	for i := 1; i < len(standings); i++ {
+		s := &standings[i]
+		switch {
+		case s.Rank == 0 || s.Checkpoints == 0:
+		case s.Status == "Finished":
+			s.Gap = s.Elapsed - standings[0].Elapsed
+		case len(leader.times) >= s.Checkpoints:
+			s.Gap = s.Elapsed - leader.times[s.Checkpoints-1]
+		}
+	}
+	return standings
+}
+
+// ranked reports whether the competitor with the status at the moment is ranked
+func ranked(status string) bool {
+	return status == "Finished" || status == "Started"
+}
+
+// trace collects checkpoints passed by the competitor from the accepted events
+func trace(comp *process.Competitor) progress {
+	p := progress{comp: comp}
+	laps := 0
+	for _, entry := range comp.Trace {
+		switch entry.Event.EventID {
+		case process.EventOnFiringRange:
+			rangeID, _ := strconv.Atoi(entry.Event.ExtraParams[0])
+			p.times = append(p.times, entry.Time.Sub(comp.TimingStart))
+			p.lastCP = Checkpoint{Lap: laps + 1, Range: rangeID}
+		case process.EventLapEnded:
+			laps++
+			p.times = append(p.times, entry.Time.Sub(comp.TimingStart))
+			p.lastCP = Checkpoint{Lap: laps}
+		}
+	}
+	return p
+}
+
+// WriteStandings writes the live standings in the language of the catalog: rank, competitor, the latest checkpoint,
+// time at it, gap to the leader and projected total time. Competitors without rank show only the status
+func WriteStandings(w io.Writer, catalog *i18n.Catalog, standings []Standing) error {
+	if _, err := fmt.Fprintln(w, catalog.LiveHeader); err != nil {
+		return fmt.Errorf("WriteStandings: %w", err)
+	}
+	for _, s := range standings {
+		line := fmt.Sprintf("- %d %s", s.CompetitorID, catalog.Status(s.Status))
+		if s.Rank != 0 {
+			line = fmt.Sprintf("%d %d %s [%s] %s %s", s.Rank, s.CompetitorID, checkpoint(catalog, s),
+				duration(catalog, s.Elapsed), gap(catalog, s), duration(catalog, s.Projected))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("WriteStandings: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkpoint describes the latest checkpoint of the competitor
+func checkpoint(catalog *i18n.Catalog, s Standing) string {
+	switch {
+	case s.Status == "Finished" || s.Checkpoints == 0:
+		return "{" + catalog.Status(s.Status) + "}"
+	case s.Checkpoint.Range != 0:
+		return "{" + fmt.Sprintf(catalog.LiveRange, s.Checkpoint.Range, s.Checkpoint.Lap) + "}"
+	}
+	return "{" + fmt.Sprintf(catalog.LiveLap, s.Checkpoint.Lap) + "}"
+}
+
+// gap formats time behind the leader, the leader has no gap
+func gap(catalog *i18n.Catalog, s Standing) string {
+	switch {
+	case s.Gap > 0:
+		return "+" + duration(catalog, s.Gap)
+	case s.Gap < 0:
+		return "-" + duration(catalog, -s.Gap)
+	}
+	return "-"
+}
+
+// duration formats duration in the locale, zero duration is unknown
+func duration(catalog *i18n.Catalog, d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return catalog.Duration(process.FormatDuration(d))
+}
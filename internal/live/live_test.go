@@ -0,0 +1,122 @@
+package live
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/process"
+	"TelecomTask/internal/timestamp"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testConfig = &config.Config{Laps: 2, LapLen: 1000, PenaltyLen: 100, FiringLines: 1, Start: "10:00:00.000", StartDelta: "00:00:30"}
+
+// testEvents are the race: competitor 1 is faster on the lap with 5 penalty laps due, competitor 2 is faster
+// on the way to the range, runs the penalty laps and then leaves the race, competitor 3 does not start
+var testEvents = []process.Event{
+	{Time: "09:30:00.000", EventID: process.EventRegistered, CompetitorID: 1},
+	{Time: "09:30:01.000", EventID: process.EventRegistered, CompetitorID: 2},
+	{Time: "09:30:02.000", EventID: process.EventRegistered, CompetitorID: 3},
+	{Time: "09:40:00.000", EventID: process.EventStartTimeDrawn, CompetitorID: 1, ExtraParams: []string{"10:00:00.000"}},
+	{Time: "09:40:01.000", EventID: process.EventStartTimeDrawn, CompetitorID: 2, ExtraParams: []string{"10:01:00.000"}},
+	{Time: "09:40:02.000", EventID: process.EventStartTimeDrawn, CompetitorID: 3, ExtraParams: []string{"10:02:00.000"}},
+	{Time: "09:59:00.000", EventID: process.EventOnStartLine, CompetitorID: 1},
+	{Time: "10:00:00.000", EventID: process.EventStarted, CompetitorID: 1},
+	{Time: "10:00:30.000", EventID: process.EventOnStartLine, CompetitorID: 2},
+	{Time: "10:01:00.000", EventID: process.EventStarted, CompetitorID: 2},
+	{Time: "10:04:00.000", EventID: process.EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"1"}},
+	{Time: "10:04:30.000", EventID: process.EventOnFiringRange, CompetitorID: 2, ExtraParams: []string{"1"}},
+	{Time: "10:04:40.000", EventID: process.EventLeftFiringRange, CompetitorID: 1},
+	{Time: "10:05:00.000", EventID: process.EventLeftFiringRange, CompetitorID: 2},
+	{Time: "10:05:05.000", EventID: process.EventEnteredPenalty, CompetitorID: 2},
+	{Time: "10:06:05.000", EventID: process.EventLeftPenalty, CompetitorID: 2},
+	{Time: "10:08:00.000", EventID: process.EventLapEnded, CompetitorID: 1},
+	{Time: "10:09:30.000", EventID: process.EventLapEnded, CompetitorID: 2},
+	{Time: "10:12:00.000", EventID: process.EventCannotContinue, CompetitorID: 2, ExtraParams: []string{"broken ski"}},
+}
+
+// TestStandings tests ranking at the same checkpoint, gaps and projected times at different moments of the race
+func TestStandings(t *testing.T) {
+	type expected struct {
+		id         int
+		rank       int
+		status     string
+		checkpoint Checkpoint
+		gap        time.Duration
+		projected  time.Duration
+	}
+	tests := []struct {
+		name     string
+		at       string
+		expected []expected
+	}{
+		{"before start", "09:50:00.000", []expected{
+			{1, 0, "Registered", Checkpoint{}, 0, 0},
+			{2, 0, "Registered", Checkpoint{}, 0, 0},
+			{3, 0, "Registered", Checkpoint{}, 0, 0},
+		}},
+		{"on the firing range", "10:04:45.000", []expected{
+			{2, 1, "Started", Checkpoint{Lap: 1, Range: 1}, 0, 0},
+			{1, 2, "Started", Checkpoint{Lap: 1, Range: 1}, 30 * time.Second, 0},
+			{3, 0, "NotStarted", Checkpoint{}, 0, 0},
+		}},
+		{"first lap of one", "10:09:00.000", []expected{
+			{1, 1, "Started", Checkpoint{Lap: 1}, 0, 24 * time.Minute},
+			{2, 2, "Started", Checkpoint{Lap: 1, Range: 1}, -30 * time.Second, 0},
+			{3, 0, "NotStarted", Checkpoint{}, 0, 0},
+		}},
+		{"first lap of both", "10:10:00.000", []expected{
+			{1, 1, "Started", Checkpoint{Lap: 1}, 0, 24 * time.Minute},
+			{2, 2, "Started", Checkpoint{Lap: 1}, 30 * time.Second, 17 * time.Minute},
+			{3, 0, "NotStarted", Checkpoint{}, 0, 0},
+		}},
+		{"out of the race", "10:15:00.000", []expected{
+			{1, 1, "Started", Checkpoint{Lap: 1}, 0, 24 * time.Minute},
+			{2, 0, "NotFinished", Checkpoint{Lap: 1}, 0, 0},
+			{3, 0, "NotStarted", Checkpoint{}, 0, 0},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, err := timestamp.Resolve(tt.at, time.Time{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			standings := Standings(testConfig, testEvents, at)
+			if len(standings) != len(tt.expected) {
+				t.Fatalf("Expected %d standings, got %d", len(tt.expected), len(standings))
+			}
+			for i, e := range tt.expected {
+				s := standings[i]
+				if s.CompetitorID != e.id || s.Rank != e.rank || s.Status != e.status || s.Checkpoint != e.checkpoint ||
+					s.Gap != e.gap || s.Projected != e.projected {
+					t.Errorf("Standing %d: expected %+v, got %+v", i+1, e, s)
+				}
+			}
+		})
+	}
+}
+
+// TestWriteStandings tests localized lines of ranked and unranked competitors
+func TestWriteStandings(t *testing.T) {
+	catalog, _ := i18n.Get("en")
+	at, err := timestamp.Resolve("10:09:00.000", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStandings(&buf, catalog, Standings(testConfig, testEvents, at)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := strings.Join([]string{
+		catalog.LiveHeader,
+		"1 1 {lap 1} [00:08:00.000] - 00:24:00.000",
+		"2 2 {firing range 1, lap 1} [00:03:30.000] -00:00:30.000 -",
+		"- 3 NotStarted",
+	}, "\n") + "\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"TelecomTask/internal/config"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestNewWriter tests structured output of the logger
+func TestNewWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewWriter(&buf, config.LogConfig{Level: "info", Format: "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	logger.Debug("hidden")
+	logger.Info("The competitor(1) registered", zap.Int("event_id", 1), zap.Int("competitor", 1))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line, got %d: %s", len(lines), buf.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Invalid json: %v", err)
+	}
+	if entry["msg"] != "The competitor(1) registered" || entry["event_id"] != 1.0 || entry["competitor"] != 1.0 {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+	if _, ok := entry["ts"]; ok {
+		t.Errorf("Expected no wall clock time, got %v", entry["ts"])
+	}
+}
+
+// TestNewInvalid tests validation of log config
+func TestNewInvalid(t *testing.T) {
+	if _, err := New(config.LogConfig{Level: "loud", Format: "console", Outputs: []string{"stderr"}}); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+	if _, err := New(config.LogConfig{Level: "info", Format: "xml", Outputs: []string{"stderr"}}); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+// TestNewFile tests that log file is rewritten on every run
+func TestNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.log")
+	if err := os.WriteFile(path, []byte("previous run\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logger, err := New(config.LogConfig{Level: "info", Format: "console", Outputs: []string{path}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	logger.Info("new run")
+	_ = logger.Sync()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "previous run") || !strings.Contains(string(content), "new run") {
+		t.Errorf("Unexpected log file content: %s", content)
+	}
+}
@@ -0,0 +1,62 @@
+package pipeline
+
+import (
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/process"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// WriteExplanation writes the report line of the competitor followed by the events behind every figure of it:
+// start and end of every lap, entering and leaving every penalty laps visit, firing range visits and hits.
+// Events are looked up by line number among events of the race
+func WriteExplanation(w io.Writer, catalog *i18n.Catalog, comp *process.Competitor, report process.Report,
+	events []process.Event) error {
+	byLine := make(map[int]process.Event, len(events))
+	for _, event := range events {
+		byLine[event.Line] = event
+	}
+	lines := []string{FormatReport(catalog, report)}
+	for i, lap := range comp.LapLines {
+		lines = append(lines, fmt.Sprintf(catalog.ExplainLap, i+1, catalog.Duration(process.FormatDuration(comp.LapTimes[i]))))
+		lines = append(lines, explainEvents(byLine, lap[:])...)
+	}
+	for i, penalty := range comp.PenaltyLines {
+		lines = append(lines, fmt.Sprintf(catalog.ExplainPenalty, i+1, catalog.Duration(process.FormatDuration(comp.PenaltyTimes[i]))))
+		lines = append(lines, explainEvents(byLine, penalty[:])...)
+	}
+	lines = append(lines, fmt.Sprintf(catalog.ExplainHitsShots, report.HitsShots))
+	shots := append(append([]int{}, comp.ShotLines...), comp.HitLines...)
+	sort.Ints(shots)
+	lines = append(lines, explainEvents(byLine, shots)...)
+	if comp.Adjusted {
+		adjustment := catalog.Status(comp.Status)
+		if comp.TimeAdjustment > 0 {
+			adjustment = "+" + comp.TimeAdjustment.String()
+		} else if comp.TimeAdjustment < 0 {
+			adjustment = comp.TimeAdjustment.String()
+		}
+		lines = append(lines, fmt.Sprintf(catalog.ExplainJury, adjustment))
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("WriteExplanation: %w", err)
+		}
+	}
+	return nil
+}
+
+// explainEvents formats events with given line numbers, an event without line number is shown as unknown
+func explainEvents(byLine map[int]process.Event, lines []int) []string {
+	explained := make([]string, 0, len(lines))
+	for _, line := range lines {
+		event, ok := byLine[line]
+		if line == 0 || !ok {
+			explained = append(explained, fmt.Sprintf("%8s ?", "-"))
+			continue
+		}
+		explained = append(explained, fmt.Sprintf("%8d %s", line, event))
+	}
+	return explained
+}
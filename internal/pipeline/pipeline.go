@@ -0,0 +1,197 @@
+package pipeline
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/journal"
+	"TelecomTask/internal/jury"
+	"TelecomTask/internal/process"
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// formatTotalTime returns localized total time or status of the competitor
+func formatTotalTime(catalog *i18n.Catalog, totalTime string) string {
+	if _, ok := catalog.Statuses[totalTime]; ok {
+		return catalog.Status(totalTime)
+	}
+	return catalog.Duration(totalTime)
+}
+
+// formatLapDetails formats time and speed of every lap
+func formatLapDetails(catalog *i18n.Catalog, lapDetails []process.LapDetail) string {
+	laps := make([]string, len(lapDetails))
+	for i, lap := range lapDetails {
+		if lap.Time == "" {
+			laps[i] = "{ }"
+			continue
+		}
+		laps[i] = fmt.Sprintf("{%s %s}", catalog.Duration(lap.Time), catalog.Number(lap.Speed, 3))
+	}
+	return "[" + strings.Join(laps, " ") + "]"
+}
+
+// FormatReport formats single line of the resulting table in the language of the catalog.
+// The reason of a rejected finish follows in parentheses
+func FormatReport(catalog *i18n.Catalog, r process.Report) string {
+	line := fmt.Sprintf("[%s] %d %s %s %s %s %s/%s",
+		formatTotalTime(catalog, r.TotalTime), r.CompetitorID, formatLapDetails(catalog, r.LapDetails),
+		catalog.Duration(r.PenaltyTime), catalog.Number(r.PenaltySpeed, 3), r.HitsShots,
+		catalog.Duration(r.ScheduledStart), catalog.Duration(r.ActualStart))
+	if r.Start != "" {
+		line += " (" + catalog.Reason(r.Start) + ")"
+	}
+	if r.Reason != "" {
+		line += " (" + catalog.Reason(r.Reason) + ")"
+	}
+	if r.Adjusted {
+		line += " " + AdjustedMark
+	}
+	return line
+}
+
+// AdjustedMark marks results changed by the jury in the resulting table
+const AdjustedMark = "*"
+
+// WriteReport writes the resulting table in the language of the catalog
+func WriteReport(w io.Writer, catalog *i18n.Catalog, reports []process.Report) error {
+	if _, err := fmt.Fprintln(w, catalog.ReportHeader); err != nil {
+		return fmt.Errorf("WriteReport: %w", err)
+	}
+	for _, r := range reports {
+		if _, err := fmt.Fprintln(w, FormatReport(catalog, r)); err != nil {
+			return fmt.Errorf("WriteReport: %w", err)
+		}
+	}
+	return nil
+}
+
+var (
+	reportLine = regexp.MustCompile(`^\[([^\]]*)\] (\d+) \[(.*)\] (\S+) (\S+) (\d+/\d+) (\S+)/(\S+)(?: \(([^()]*)\))?(?: \(([^()]*)\))?( \*)?$`)
+	lapDetail  = regexp.MustCompile(`\{(\S*) ?(\S*)\}`)
+)
+
+// parseReport parses single line of the resulting table written in the language of the catalog
+func parseReport(catalog *i18n.Catalog, line string) (process.Report, error) {
+	m := reportLine.FindStringSubmatch(line)
+	if m == nil {
+		return process.Report{}, fmt.Errorf("invalid report line: %s", line)
+	}
+	id, err := strconv.Atoi(m[2])
+	if err != nil {
+		return process.Report{}, fmt.Errorf("invalid competitor id: %s", m[2])
+	}
+	penaltySpeed, err := catalog.ParseNumber(m[5])
+	if err != nil {
+		return process.Report{}, fmt.Errorf("invalid penalty speed: %w", err)
+	}
+	report := process.Report{
+		CompetitorID:   id,
+		TotalTime:      catalog.StatusKey(catalog.ParseDuration(m[1])),
+		PenaltyTime:    catalog.ParseDuration(m[4]),
+		PenaltySpeed:   penaltySpeed,
+		HitsShots:      m[6],
+		ScheduledStart: catalog.ParseDuration(m[7]),
+		ActualStart:    catalog.ParseDuration(m[8]),
+		Adjusted:       m[11] != "",
+	}
+	for _, note := range m[9:11] {
+		if key := catalog.ReasonKey(note); process.IsStartOutcome(key) {
+			report.Start = key
+		} else if key != "" {
+			report.Reason = key
+		}
+	}
+	for _, lap := range lapDetail.FindAllStringSubmatch(m[3], -1) {
+		if lap[1] == "" {
+			report.LapDetails = append(report.LapDetails, process.LapDetail{})
+			continue
+		}
+		speed, err := catalog.ParseNumber(lap[2])
+		if err != nil {
+			return process.Report{}, fmt.Errorf("invalid lap speed: %w", err)
+		}
+		report.LapDetails = append(report.LapDetails, process.LapDetail{Time: catalog.ParseDuration(lap[1]), Speed: speed})
+	}
+	return report, nil
+}
+
+// ReadReport reads the resulting table written by WriteReport in any supported language.
+// Lines before the table header, e.g. the output log, are skipped
+func ReadReport(r io.Reader) ([]process.Report, error) {
+	scanner := bufio.NewScanner(r)
+	var catalog *i18n.Catalog
+	var reports []process.Report
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if catalog == nil {
+			for _, locale := range i18n.Locales() {
+				if c, _ := i18n.Get(locale); c.ReportHeader == line {
+					catalog = c
+				}
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		report, err := parseReport(catalog, line)
+		if err != nil {
+			return nil, fmt.Errorf("ReadReport: line %d: %w", lineNum, err)
+		}
+		reports = append(reports, report)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ReadReport: %w", err)
+	}
+	if catalog == nil {
+		return nil, fmt.Errorf("ReadReport: report header not found")
+	}
+	return reports, nil
+}
+
+// Run processes events of the whole race, the processor writes the output log.
+// Returns final state of competitors and the resulting table
+func Run(cfg *config.Config, events []process.Event, logger *zap.Logger) (map[int]*process.Competitor, []process.Report) {
+	processor := process.NewProcessor(cfg, logger)
+	for _, event := range events {
+		processor.Process(event)
+	}
+	processor.Flush()
+	return processor.Competitors(), process.GenerateReport(processor.Competitors(), cfg)
+}
+
+// Judge applies jury decisions to the competitors of the processed race and returns the adjusted resulting table
+func Judge(cfg *config.Config, competitors map[int]*process.Competitor, decisions []jury.Decision,
+	logger *zap.Logger) []process.Report {
+	jury.Apply(competitors, cfg.Laps, decisions, logger)
+	return process.GenerateReport(competitors, cfg)
+}
+
+// RunJournaled works like Run, but first restores the state from events recorded in the journal
+// and then appends every new event to the journal before processing it
+func RunJournaled(cfg *config.Config, events []process.Event, logger *zap.Logger,
+	j *journal.Journal) (map[int]*process.Competitor, []process.Report, error) {
+	remaining, err := journal.Remaining(j.Events(), events)
+	if err != nil {
+		return nil, nil, fmt.Errorf("RunJournaled: %w", err)
+	}
+	processor := process.NewProcessor(cfg, logger)
+	for _, event := range j.Events() {
+		processor.Process(event)
+	}
+	for _, event := range remaining {
+		if err = j.Append(event); err != nil {
+			return nil, nil, fmt.Errorf("RunJournaled: %w", err)
+		}
+		processor.Process(event)
+	}
+	processor.Flush()
+	return processor.Competitors(), process.GenerateReport(processor.Competitors(), cfg), nil
+}
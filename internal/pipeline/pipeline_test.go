@@ -0,0 +1,190 @@
+package pipeline
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/journal"
+	"TelecomTask/internal/jury"
+	"TelecomTask/internal/logger"
+	"TelecomTask/internal/process"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// racesDir contains fixtures of full races: config.json, events and optional decisions.json of the jury, with golden output.log.golden and report.golden
+const racesDir = "testdata/races"
+
+// compareGolden compares actual output with golden file or rewrites the golden file with -update flag
+func compareGolden(t *testing.T, path string, actual []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, actual, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading golden file, run tests with -update to create it: %v", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("Output differs from %s, run tests with -update to accept it\nexpected:\n%s\ngot:\n%s", path, expected, actual)
+	}
+}
+
+// TestRunGolden runs every race fixture through the full pipeline and compares results with golden files
+func TestRunGolden(t *testing.T) {
+	races, err := os.ReadDir(racesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, race := range races {
+		if !race.IsDir() {
+			continue
+		}
+		dir := filepath.Join(racesDir, race.Name())
+		t.Run(race.Name(), func(t *testing.T) {
+			cfg, err := config.New(filepath.Join(dir, "config.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			events, err := process.LoadEvents(filepath.Join(dir, "events"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var logs, report bytes.Buffer
+			raceLogger, err := logger.NewWriter(&logs, config.LogConfig{Level: "debug", Format: "console"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			catalog, err := i18n.Get(cfg.Locale)
+			if err != nil {
+				t.Fatal(err)
+			}
+			competitors, reports := Run(cfg, events, raceLogger)
+			if decisions, err := jury.Load(filepath.Join(dir, "decisions.json")); err == nil {
+				reports = Judge(cfg, competitors, decisions, raceLogger)
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				t.Fatal(err)
+			}
+			if err := WriteReport(&report, catalog, reports); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			compareGolden(t, filepath.Join(dir, "output.log.golden"), logs.Bytes())
+			compareGolden(t, filepath.Join(dir, "report.golden"), report.Bytes())
+
+			read, err := ReadReport(bytes.NewReader(append(logs.Bytes(), report.Bytes()...)))
+			if err != nil {
+				t.Fatalf("Unexpected error reading report: %v", err)
+			}
+			var rewritten bytes.Buffer
+			if err := WriteReport(&rewritten, catalog, read); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(rewritten.Bytes(), report.Bytes()) {
+				t.Errorf("Report read back differs:\n%s", rewritten.Bytes())
+			}
+		})
+	}
+}
+
+// TestRunJournaled tests that a race interrupted by a crash is restored from the journal without double-counting
+func TestRunJournaled(t *testing.T) {
+	dir := filepath.Join(racesDir, "sample")
+	cfg, err := config.New(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := process.LoadEvents(filepath.Join(dir, "events"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "journal")
+
+	j, err := journal.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := RunJournaled(cfg, events[:len(events)/2], zap.NewNop(), j); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err = journal.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = j.Close()
+	}()
+	competitors, reports, err := RunJournaled(cfg, events, zap.NewNop(), j)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(j.Events()) != len(events) {
+		t.Errorf("Expected %d journaled events, got %d", len(events), len(j.Events()))
+	}
+	expectedCompetitors, expected := Run(cfg, events, zap.NewNop())
+	if !reflect.DeepEqual(reports, expected) {
+		t.Errorf("Restored race differs from uninterrupted one\nexpected: %v\ngot: %v", expected, reports)
+	}
+	if !reflect.DeepEqual(competitors, expectedCompetitors) {
+		t.Error("Restored competitors differ from uninterrupted ones, line numbers of journaled events are lost")
+	}
+}
+
+// TestWriteExplanation tests that every figure of the report line is explained by events with their line numbers
+func TestWriteExplanation(t *testing.T) {
+	dir := filepath.Join(racesDir, "jury")
+	cfg, err := config.New(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := process.LoadEvents(filepath.Join(dir, "events"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decisions, err := jury.Load(filepath.Join(dir, "decisions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	competitors, _ := Run(cfg, events, zap.NewNop())
+	reports := Judge(cfg, competitors, decisions, zap.NewNop())
+	catalog, _ := i18n.Get("en")
+
+	var buf bytes.Buffer
+	if err := WriteExplanation(&buf, catalog, competitors[reports[1].CompetitorID], reports[1], events); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if lines[0] != FormatReport(catalog, reports[1]) || lines[len(lines)-1] != "Adjusted by the jury: +1m0s" {
+		t.Fatalf("Unexpected explanation:\n%s", buf.String())
+	}
+	for _, line := range lines {
+		var number int
+		if _, err := fmt.Sscanf(line, "%d", &number); err != nil {
+			continue
+		}
+		if event := events[number-1].String(); !strings.HasSuffix(line, " "+event) {
+			t.Errorf("Line %d explained as %q, expected event %q", number, line, event)
+		}
+	}
+	if n := strings.Count(buf.String(), "Lap "); n != cfg.Laps {
+		t.Errorf("Expected %d laps explained, got %d:\n%s", cfg.Laps, n, buf.String())
+	}
+}
@@ -0,0 +1,135 @@
+package process
+
+import (
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/timestamp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Incoming and outgoing event identifiers
+const (
+	EventRegistered      = 1
+	EventStartTimeDrawn  = 2
+	EventOnStartLine     = 3
+	EventStarted         = 4
+	EventOnFiringRange   = 5
+	EventTargetHit       = 6
+	EventLeftFiringRange = 7
+	EventEnteredPenalty  = 8
+	EventLeftPenalty     = 9
+	EventLapEnded        = 10
+	EventCannotContinue  = 11
+	EventDisqualified    = 32
+	EventFinished        = 33
+	EventDidNotStart     = 34
+)
+
+// ParamKind is the kind of event extra parameter
+type ParamKind int
+
+const (
+	ParamTime ParamKind = iota // time in HH:MM:SS.sss or RFC 3339 format
+	ParamInt                   // integer number
+	ParamText                  // free text, takes the rest of the line, may be omitted when it is the last parameter
+)
+
+// EventType describes event: its parameters and human-readable message.
+// Message template may contain {competitor}, {param} (the first extra parameter) and {params} (all extra parameters)
+type EventType struct {
+	ID       int
+	Name     string
+	Outgoing bool
+	Params   []ParamKind
+	Message  string
+}
+
+// eventTypes is the catalog of all known events, adding an event type takes one entry here
+var eventTypes = map[int]EventType{
+	EventRegistered:      {ID: EventRegistered, Name: "registered", Message: "The competitor({competitor}) registered"},
+	EventStartTimeDrawn:  {ID: EventStartTimeDrawn, Name: "start_time_drawn", Params: []ParamKind{ParamTime}, Message: "The start time for competitor({competitor}) was set by a draw to {param}"},
+	EventOnStartLine:     {ID: EventOnStartLine, Name: "on_start_line", Message: "The competitor({competitor}) is on the start line"},
+	EventStarted:         {ID: EventStarted, Name: "started", Message: "The competitor({competitor}) has started"},
+	EventOnFiringRange:   {ID: EventOnFiringRange, Name: "on_firing_range", Params: []ParamKind{ParamInt}, Message: "The competitor({competitor}) is on the firing range({param})"},
+	EventTargetHit:       {ID: EventTargetHit, Name: "target_hit", Params: []ParamKind{ParamInt}, Message: "The target({param}) has been hit by competitor({competitor})"},
+	EventLeftFiringRange: {ID: EventLeftFiringRange, Name: "left_firing_range", Message: "The competitor({competitor}) left the firing range"},
+	EventEnteredPenalty:  {ID: EventEnteredPenalty, Name: "entered_penalty", Message: "The competitor({competitor}) entered the penalty laps"},
+	EventLeftPenalty:     {ID: EventLeftPenalty, Name: "left_penalty", Message: "The competitor({competitor}) left the penalty laps"},
+	EventLapEnded:        {ID: EventLapEnded, Name: "lap_ended", Message: "The competitor({competitor}) ended the main lap"},
+	EventCannotContinue:  {ID: EventCannotContinue, Name: "cannot_continue", Params: []ParamKind{ParamText}, Message: "The competitor({competitor}) can't continue: {params}"},
+	EventDisqualified:    {ID: EventDisqualified, Name: "disqualified", Outgoing: true, Message: "The competitor({competitor}) is disqualified"},
+	EventFinished:        {ID: EventFinished, Name: "finished", Outgoing: true, Message: "The competitor({competitor}) has finished"},
+	EventDidNotStart:     {ID: EventDidNotStart, Name: "did_not_start", Outgoing: true, Message: "The competitor({competitor}) did not start"},
+}
+
+// LookupEventType returns description of the event type by its id
+func LookupEventType(id int) (EventType, bool) {
+	eventType, ok := eventTypes[id]
+	return eventType, ok
+}
+
+// Validate checks that event has all parameters required by its type. Extra parameters are allowed
+func (t EventType) Validate(event Event) error {
+	required := len(t.Params)
+	if required > 0 && t.Params[required-1] == ParamText {
+		required--
+	}
+	if len(event.ExtraParams) < required {
+		return fmt.Errorf("event %s expects %d extra params, got %d", t.Name, required, len(event.ExtraParams))
+	}
+	for i, kind := range t.Params[:min(len(t.Params), len(event.ExtraParams))] {
+		param := event.ExtraParams[i]
+		switch kind {
+		case ParamTime:
+			if _, _, err := timestamp.Parse(param); err != nil {
+				return fmt.Errorf("event %s: invalid time param: %s", t.Name, param)
+			}
+		case ParamInt:
+			if _, err := strconv.Atoi(param); err != nil {
+				return fmt.Errorf("event %s: invalid integer param: %s", t.Name, param)
+			}
+		}
+	}
+	return nil
+}
+
+// Format fills message template of the event type with event data
+func (t EventType) Format(template string, event Event) string {
+	param := ""
+	if len(event.ExtraParams) > 0 {
+		param = event.ExtraParams[0]
+	}
+	return strings.NewReplacer(
+		"{competitor}", strconv.Itoa(event.CompetitorID),
+		"{param}", param,
+		"{params}", strings.Join(event.ExtraParams, " "),
+	).Replace(template)
+}
+
+// Message returns human-readable description of the event
+func (e Event) Message() string {
+	eventType, ok := LookupEventType(e.EventID)
+	if !ok {
+		return fmt.Sprintf("Unknown event(%d) of competitor(%d)", e.EventID, e.CompetitorID)
+	}
+	return eventType.Format(eventType.Message, e)
+}
+
+// LocalizedMessage returns description of the event using message template of the catalog,
+// time parameters are written with the decimal separator of the catalog
+func (e Event) LocalizedMessage(catalog *i18n.Catalog) string {
+	eventType, ok := LookupEventType(e.EventID)
+	if !ok {
+		return e.Message()
+	}
+	localized := e
+	localized.ExtraParams = slices.Clone(e.ExtraParams)
+	for i, kind := range eventType.Params {
+		if kind == ParamTime && i < len(localized.ExtraParams) {
+			localized.ExtraParams[i] = catalog.Duration(localized.ExtraParams[i])
+		}
+	}
+	return eventType.Format(catalog.EventTemplate(e.EventID, eventType.Message), localized)
+}
@@ -0,0 +1,86 @@
+package process
+
+import (
+	"fmt"
+	"time"
+)
+
+// Reasons of a rejected finish shown in the report
+const (
+	ReasonRangesMissing     = "ranges_missing"
+	ReasonPenaltyNotServed  = "penalty_not_served"
+	ReasonLapInPenalty      = "lap_in_penalty"
+	ReasonNegativeDuration  = "negative_duration"
+	ReasonPenaltyNotEntered = "penalty_not_entered"
+)
+
+// TraceEntry is an accepted event of the competitor with its time on the reference clock
+type TraceEntry struct {
+	Event Event
+	Time  time.Time
+}
+
+// FinishError describes why the race trace of a competitor breaks the course rules
+type FinishError struct {
+	Reason string
+	Detail string
+}
+
+func (e *FinishError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, e.Detail)
+}
+
+// finishError creates FinishError with formatted detail
+func finishError(reason, format string, args ...interface{}) *FinishError {
+	return &FinishError{Reason: reason, Detail: fmt.Sprintf(format, args...)}
+}
+
+// validateTrace checks the whole race of the competitor against the course rules when the last lap ends:
+// every firing range is visited, penalty laps are served before the lap ends and no duration is negative
+func validateTrace(firingLines int, trace []TraceEntry) error {
+	var lapStart, penaltyStart time.Time
+	lapCount, rangeCount, pending, hits := 0, 0, 0, 0
+	inPenalty := false
+	for _, entry := range trace {
+		switch entry.Event.EventID {
+		case EventStarted:
+			lapStart = entry.Time
+		case EventOnFiringRange:
+			rangeCount++
+			hits = 0
+		case EventTargetHit:
+			hits++
+		case EventLeftFiringRange:
+			pending += TargetsPerRange - hits
+		case EventEnteredPenalty:
+			inPenalty = true
+			penaltyStart = entry.Time
+		case EventLeftPenalty:
+			if !inPenalty {
+				return finishError(ReasonPenaltyNotEntered, "left penalty laps at %s without entering them", entry.Event.Time)
+			}
+			if entry.Time.Before(penaltyStart) {
+				return finishError(ReasonNegativeDuration, "penalty laps left at %s before entering them", entry.Event.Time)
+			}
+			inPenalty = false
+			pending = 0
+		case EventLapEnded:
+			lapCount++
+			if inPenalty {
+				return finishError(ReasonLapInPenalty, "lap %d ended at %s on penalty laps", lapCount, entry.Event.Time)
+			}
+			if pending > 0 {
+				return finishError(ReasonPenaltyNotServed, "lap %d ended at %s with %d penalty laps not served",
+					lapCount, entry.Event.Time, pending)
+			}
+			if entry.Time.Before(lapStart) {
+				return finishError(ReasonNegativeDuration, "lap %d ended at %s before it started", lapCount, entry.Event.Time)
+			}
+			lapStart = entry.Time
+		}
+	}
+	if rangeCount != firingLines {
+		return finishError(ReasonRangesMissing, "visited %d of %d firing ranges", rangeCount, firingLines)
+	}
+	return nil
+}
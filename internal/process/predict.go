@@ -0,0 +1,109 @@
+package process
+
+import (
+	"TelecomTask/internal/config"
+	"sort"
+	"time"
+)
+
+// Prediction is the expected result of the competitor on course, zero if it cannot be predicted yet
+type Prediction struct {
+	// Finish is the expected finish time on the reference clock
+	Finish time.Time
+	// Total is the expected total time the same way as the report counts it
+	Total time.Duration
+}
+
+// Predict estimates the result of the competitor on course after the first lap: the remaining laps are run
+// at the average pace of the completed laps without penalty laps, and on the remaining firing ranges the competitor
+// misses as often as on the ranges behind. Every expected miss and every penalty lap due is run at the pace
+// of the penalty laps run so far, or at the lap pace if there were none. Competitors who are not on course
+// or have not completed a lap get zero prediction
+func Predict(cfg *config.Config, comp *Competitor) Prediction {
+	laps := len(comp.LapTimes)
+	if comp.Status != "Started" || laps == 0 || cfg.LapLen == 0 {
+		return Prediction{}
+	}
+
+	// penalty time run on the completed laps and on the current lap
+	var lapsPenalty, currentPenalty time.Duration
+	var penaltyStart time.Time
+	for _, entry := range comp.Trace {
+		switch entry.Event.EventID {
+		case EventEnteredPenalty:
+			penaltyStart = entry.Time
+		case EventLeftPenalty:
+			currentPenalty += entry.Time.Sub(penaltyStart)
+		case EventLapEnded:
+			lapsPenalty += currentPenalty
+			currentPenalty = 0
+		}
+	}
+	var course, penaltyTime time.Duration
+	for _, lapTime := range comp.LapTimes {
+		course += lapTime
+	}
+	for _, pt := range comp.PenaltyTimes {
+		penaltyTime += pt
+	}
+	pace := (course - lapsPenalty) / time.Duration(laps)
+	penaltyLap := time.Duration(float64(pace) * float64(cfg.PenaltyLen) / float64(cfg.LapLen))
+	if comp.PenaltyLapsServed > 0 {
+		penaltyLap = penaltyTime / time.Duration(comp.PenaltyLapsServed)
+	}
+
+	shots, hits := 0, 0
+	for rangeID, rangeShots := range comp.Shots {
+		if comp.OnFiringRange && rangeID == comp.FiringRange {
+			continue
+		}
+		shots += rangeShots
+		hits += len(comp.Hits[rangeID])
+	}
+	ranges := cfg.FiringLines - len(comp.VisitedRanges)
+	if comp.OnFiringRange {
+		ranges++
+	}
+	penaltyLaps := float64(comp.PenaltyLaps)
+	if shots > 0 {
+		penaltyLaps += float64(ranges*TargetsPerRange) * float64(shots-hits) / float64(shots)
+	}
+	expectedPenalty := time.Duration(penaltyLaps * float64(penaltyLap))
+
+	remaining := time.Duration(cfg.Laps-laps)*pace + currentPenalty + expectedPenalty
+	return Prediction{
+		Finish: comp.LastLapTime.Add(remaining),
+		Total:  TotalTime(comp) + remaining + expectedPenalty,
+	}
+}
+
+// PredictedRanks returns expected places of the competitors on course by id. Predicted total times are ranked
+// together with total times of finished competitors, competitors without prediction are not ranked
+func PredictedRanks(competitors map[int]*Competitor) map[int]int {
+	type result struct {
+		comp  *Competitor
+		total time.Duration
+	}
+	var results []result
+	for _, comp := range competitors {
+		switch {
+		case comp.Status == "Finished":
+			results = append(results, result{comp: comp, total: TotalTime(comp)})
+		case comp.Prediction.Total > 0:
+			results = append(results, result{comp: comp, total: comp.Prediction.Total})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].total != results[j].total {
+			return results[i].total < results[j].total
+		}
+		return results[i].comp.ID < results[j].comp.ID
+	})
+	ranks := make(map[int]int)
+	for i, r := range results {
+		if r.comp.Status != "Finished" {
+			ranks[r.comp.ID] = i + 1
+		}
+	}
+	return ranks
+}
@@ -2,13 +2,18 @@ package process
 
 import (
 	"TelecomTask/internal/config"
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/timestamp"
 	"bufio"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"go.uber.org/zap"
 )
 
 type Event struct {
@@ -16,8 +21,17 @@ type Event struct {
 	EventID      int
 	CompetitorID int
 	ExtraParams  []string
+	// Source is the tag of the timing device which produced the event, empty if unknown
+	Source string
+	// Seq is the sequence number of the event assigned by the source, 0 if not set
+	Seq int
+	// Line is the line number of the event in the events file, 0 if the event was not read from a file
+	Line int
 }
 
+// TargetsPerRange is the number of targets and shots on every firing range visit
+const TargetsPerRange = 5
+
 type Competitor struct {
 	ID              int
 	Registered      bool
@@ -31,8 +45,42 @@ type Competitor struct {
 	CurrentLap      int
 	PenaltyLaps     int
 	FiringRange     int
+	OnFiringRange   bool
+	VisitedRanges   []int
+	InPenalty       bool
 	LastPenaltyTime time.Time
 	LastLapTime     time.Time
+	// PenaltyLapsServed is the number of penalty laps run, one penalty loop visit serves all pending laps
+	PenaltyLapsServed int
+	// Trace is the list of accepted events of the competitor
+	Trace []TraceEntry
+	// FinishRejection is the reason of the rejected finish, empty if finish was not rejected
+	FinishRejection string
+	// TimeAdjustment is the time added to the total time by the jury
+	TimeAdjustment time.Duration
+	// Adjusted reports whether the jury changed the result of the competitor
+	Adjusted bool
+	// LapLines are line numbers of the events which started and ended every lap
+	LapLines [][2]int
+	// PenaltyLines are line numbers of the events which entered and left every penalty laps visit
+	PenaltyLines [][2]int
+	// ShotLines are line numbers of the firing range visits, every visit gives TargetsPerRange shots
+	ShotLines []int
+	// HitLines are line numbers of the accepted hits
+	HitLines []int
+	// LastLapLine and LastPenaltyLine are line numbers of the events which started the current lap and penalty laps
+	LastLapLine     int
+	LastPenaltyLine int
+	// StartOutcome is the outcome of the start out of time, empty if the competitor started in time
+	StartOutcome string
+	// StartPenalty is the time added to the total time for the start out of time
+	StartPenalty time.Duration
+	// GateTime is the time the competitor passed the start gate onto the start line
+	GateTime time.Time
+	// TimingStart is the time the first lap of the competitor is measured from
+	TimingStart time.Time
+	// Prediction is the expected result while the competitor is on course, updated after every accepted event of the competitor
+	Prediction Prediction
 }
 
 type LapDetail struct {
@@ -47,11 +95,69 @@ type Report struct {
 	PenaltyTime  string
 	PenaltySpeed float64
 	HitsShots    string
+	// Reason is the reason of the rejected finish, empty if the finish was not rejected
+	Reason string
+	// Adjusted reports whether the result was changed by the jury
+	Adjusted bool
+	// Start is the outcome of the start out of time, empty if the competitor started in time
+	Start string
+	// ScheduledStart and ActualStart are the drawn and the actual start times, "-" if unknown
+	ScheduledStart string
+	ActualStart    string
 }
 
-// parseEvent parses events from file into Event struct
-func parseEvent(line string) (Event, error) {
+// String formats event the same way as it is written in events file
+func (e Event) String() string {
+	line := fmt.Sprintf("[%s]", e.Time)
+	if e.Source != "" {
+		line += " src=" + e.Source
+	}
+	if e.Seq != 0 {
+		line += fmt.Sprintf(" seq=%d", e.Seq)
+	}
+	line += fmt.Sprintf(" %d %d", e.EventID, e.CompetitorID)
+	if len(e.ExtraParams) > 0 {
+		line += " " + strings.Join(e.ExtraParams, " ")
+	}
+	return line
+}
+
+// ErrUnknownEvent is returned by ParseEvent for event ids which are not incoming events of the catalog
+var ErrUnknownEvent = errors.New("unknown incoming event id")
+
+// parseAttributes parses optional key=value attributes written between the time and the event id
+func parseAttributes(event *Event, parts []string) ([]string, error) {
+	for len(parts) > 0 && strings.Contains(parts[0], "=") {
+		key, value, _ := strings.Cut(parts[0], "=")
+		switch key {
+		case "src":
+			event.Source = value
+		case "seq":
+			seq, err := strconv.Atoi(value)
+			if err != nil || seq <= 0 {
+				return nil, fmt.Errorf("invalid event sequence number: %s", value)
+			}
+			event.Seq = seq
+		default:
+			return nil, fmt.Errorf("unknown event attribute: %s", key)
+		}
+		parts = parts[1:]
+	}
+	return parts, nil
+}
+
+// ParseEvent parses single line of events file into Event struct.
+// The time may be followed by attributes, e.g. "[10:00:00.000] src=finish 10 1"
+func ParseEvent(line string) (Event, error) {
 	parts := strings.Fields(line)
+	var event Event
+	if len(parts) > 0 {
+		rest, err := parseAttributes(&event, parts[1:])
+		if err != nil {
+			return Event{}, err
+		}
+		parts = append(parts[:1], rest...)
+	}
 	if len(parts) < 3 {
 		return Event{}, fmt.Errorf("invalid event format: %s", line)
 	}
@@ -64,14 +170,18 @@ func parseEvent(line string) (Event, error) {
 		return Event{}, fmt.Errorf("invalid competitor id: %s", parts[2])
 	}
 
-	timeStr := strings.Trim(parts[0], "[]")
-	extraParams := parts[3:]
-	return Event{
-		Time:         timeStr,
-		EventID:      eventID,
-		CompetitorID: competitorID,
-		ExtraParams:  extraParams,
-	}, nil
+	event.Time = strings.Trim(parts[0], "[]")
+	event.EventID = eventID
+	event.CompetitorID = competitorID
+	event.ExtraParams = parts[3:]
+	eventType, ok := LookupEventType(eventID)
+	if !ok || eventType.Outgoing {
+		return Event{}, fmt.Errorf("%w: %d", ErrUnknownEvent, eventID)
+	}
+	if err := eventType.Validate(event); err != nil {
+		return Event{}, err
+	}
+	return event, nil
 }
 
 // LoadEvents loads events from file and converts them into Event slice
@@ -89,24 +199,50 @@ func LoadEvents(filename string) ([]Event, error) {
 
 	var events []Event
 	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
 		line := scanner.Text()
-		event, err := parseEvent(line)
+		event, err := ParseEvent(line)
+		if errors.Is(err, ErrUnknownEvent) {
+			zap.L().Warn("LoadEvents: unknown event skipped", zap.Int("line", lineNumber), zap.Error(err))
+			continue
+		}
 		if err != nil {
 			return nil, fmt.Errorf("LoadEvents: error parsing the file: %v", err)
 		}
+		event.Line = lineNumber
 		events = append(events, event)
 	}
 	return events, nil
 }
 
-// LogEvent logs input event with message
-func LogEvent(event Event, message string) {
-	log.Printf("[%s] %s\n", event.Time, message)
+// EventFields returns structured log fields describing the event
+func EventFields(event Event) []zap.Field {
+	name := "unknown"
+	if eventType, ok := LookupEventType(event.EventID); ok {
+		name = eventType.Name
+	}
+	fields := []zap.Field{
+		zap.String("event_time", event.Time),
+		zap.Int("event_id", event.EventID),
+		zap.String("event", name),
+		zap.Int("competitor", event.CompetitorID),
+	}
+	if event.Source != "" {
+		fields = append(fields, zap.String("source", event.Source))
+	}
+	if event.Seq != 0 {
+		fields = append(fields, zap.Int("seq", event.Seq))
+	}
+	return fields
+}
+
+// LogEvent logs event with its message in the language of the catalog
+func LogEvent(logger *zap.Logger, catalog *i18n.Catalog, event Event) {
+	logger.Info(event.LocalizedMessage(catalog), EventFields(event)...)
 }
 
-// formatDuration formats input time duration into correct format
-func formatDuration(d time.Duration) string {
+// FormatDuration formats input time duration into correct format
+func FormatDuration(d time.Duration) string {
 	hours := int(d.Hours())
 	minutes := int(d.Minutes()) % 60
 	seconds := int(d.Seconds()) % 60
@@ -114,18 +250,50 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, seconds, millis)
 }
 
+// ParseDuration parses duration formatted by FormatDuration, such as total time of the report.
+// Hours are not limited to a day
+func ParseDuration(s string) (time.Duration, error) {
+	var hours, minutes, seconds, millis int
+	if n, err := fmt.Sscanf(s, "%d:%2d:%2d.%3d", &hours, &minutes, &seconds, &millis); err != nil || n != 4 ||
+		len(s) < len("00:00:00.000") || minutes > 59 || seconds > 59 {
+		return 0, fmt.Errorf("ParseDuration: invalid duration: %s", s)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second + time.Duration(millis)*time.Millisecond, nil
+}
+
+// formatStart formats start time of the report, zero time is unknown
+func formatStart(t time.Time, dated bool) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return timestamp.Format(t, dated)
+}
+
+// TotalTime returns total time of the competitor: laps, penalty laps, start penalty and jury adjustment
+func TotalTime(comp *Competitor) time.Duration {
+	var total time.Duration
+	for _, lapTime := range comp.LapTimes {
+		total += lapTime
+	}
+	for _, penaltyTime := range comp.PenaltyTimes {
+		total += penaltyTime
+	}
+	return total + comp.TimeAdjustment + comp.StartPenalty
+}
+
 // GenerateReport generates report by map of competitors
 func GenerateReport(competitors map[int]*Competitor, config *config.Config) []Report {
-	var reports []Report
-	for _, comp := range competitors {
-		totalTime := time.Duration(0)
-		for _, lt := range comp.LapTimes {
-			totalTime += lt
-		}
-		for _, pt := range comp.PenaltyTimes {
-			totalTime += pt
-		}
+	_, dated, _ := timestamp.Parse(config.Start)
+	ids := make([]int, 0, len(competitors))
+	for id := range competitors {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
 
+	var reports []Report
+	for _, id := range ids {
+		comp := competitors[id]
 		var lapDetails []LapDetail
 		for _, lt := range comp.LapTimes {
 			speed := 0.0
@@ -133,7 +301,7 @@ func GenerateReport(competitors map[int]*Competitor, config *config.Config) []Re
 				speed = float64(config.LapLen) / lt.Seconds()
 			}
 			lapDetails = append(lapDetails, LapDetail{
-				Time:  formatDuration(lt),
+				Time:  FormatDuration(lt),
 				Speed: speed,
 			})
 		}
@@ -147,7 +315,7 @@ func GenerateReport(competitors map[int]*Competitor, config *config.Config) []Re
 		}
 		penaltySpeed := 0.0
 		if penaltyTime > 0 {
-			penaltySpeed = float64(config.PenaltyLen*len(comp.PenaltyTimes)) / penaltyTime.Seconds()
+			penaltySpeed = float64(config.PenaltyLen*comp.PenaltyLapsServed) / penaltyTime.Seconds()
 		}
 
 		totalHits, totalShots := 0, 0
@@ -159,156 +327,502 @@ func GenerateReport(competitors map[int]*Competitor, config *config.Config) []Re
 		}
 
 		report := Report{
-			CompetitorID: comp.ID,
-			TotalTime:    comp.Status,
-			LapDetails:   lapDetails,
-			PenaltyTime:  formatDuration(penaltyTime),
-			PenaltySpeed: penaltySpeed,
-			HitsShots:    fmt.Sprintf("%d/%d", totalHits, totalShots),
+			CompetitorID:   comp.ID,
+			TotalTime:      comp.Status,
+			LapDetails:     lapDetails,
+			PenaltyTime:    FormatDuration(penaltyTime),
+			PenaltySpeed:   penaltySpeed,
+			HitsShots:      fmt.Sprintf("%d/%d", totalHits, totalShots),
+			Reason:         comp.FinishRejection,
+			Adjusted:       comp.Adjusted,
+			Start:          comp.StartOutcome,
+			ScheduledStart: formatStart(comp.StartTime, dated),
+			ActualStart:    formatStart(comp.ActualStart, dated),
 		}
 		if comp.Status == "Finished" {
-			report.TotalTime = formatDuration(totalTime)
+			report.TotalTime = FormatDuration(TotalTime(comp))
 		}
 		reports = append(reports, report)
 	}
 
-	sort.Slice(reports, func(i, j int) bool {
-		if reports[i].TotalTime == "NotStarted" || reports[i].TotalTime == "NotFinished" {
+	sort.SliceStable(reports, func(i, j int) bool {
+		ti, errI := ParseDuration(reports[i].TotalTime)
+		tj, errJ := ParseDuration(reports[j].TotalTime)
+		if errI != nil {
 			return false
 		}
-		if reports[j].TotalTime == "NotStarted" || reports[j].TotalTime == "NotFinished" {
+		if errJ != nil {
 			return true
 		}
-		ti, _ := time.ParseDuration(strings.Replace(reports[i].TotalTime, ":", "h", 1) + "m" + "s")
-		tj, _ := time.ParseDuration(strings.Replace(reports[j].TotalTime, ":", "h", 1) + "m" + "s")
 		return ti < tj
 	})
 
 	return reports
 }
 
-// Events generate map of competitors and slice of outgoing events
-func Events(config *config.Config, events []Event) (map[int]*Competitor, []Event) {
-	competitors := make(map[int]*Competitor)
-	var outgoingEvents []Event
+// Processor processes incoming events one by one and keeps the state of the competition
+type Processor struct {
+	config         *config.Config
+	logger         *zap.Logger
+	catalog        *i18n.Catalog
+	competitors    map[int]*Competitor
+	outgoingEvents []Event
+	// clock is the time of the latest event, times of day are placed after it to cross midnight
+	clock time.Time
+	// sources are clock corrections of timing devices by source tag
+	sources map[string]source
+	// window is the reorder window, events are held back until the clock passes their time by the window
+	window time.Duration
+	// pending are events held back for reordering, sorted by time
+	pending []pendingEvent
+	// released is the time of the latest event applied from the reorder buffer
+	released time.Time
+	// duplicateWindow is how close in time the same event must be to be taken as a duplicate
+	duplicateWindow time.Duration
+	// seen are times of accepted events by their content without time
+	seen map[string][]time.Time
+	// seenSeq are accepted sequence numbers by source
+	seenSeq map[string]bool
+	// duplicates are dropped duplicate events
+	duplicates []Event
+	// awaitingStart are ids of competitors with drawn start time who have not come to the start line yet
+	awaitingStart map[int]bool
+	// observer is called with every accepted incoming event and every outgoing event, nil if not set
+	observer func(event Event)
+}
 
-	for _, event := range events {
-		comp, exists := competitors[event.CompetitorID]
-		if !exists {
-			comp = &Competitor{
-				ID:          event.CompetitorID,
-				Hits:        make(map[int][]int),
-				Shots:       make(map[int]int),
-				Status:      "NotStarted",
-				CurrentLap:  -1,
-				LastLapTime: time.Time{},
-			}
-			competitors[event.CompetitorID] = comp
+// pendingEvent is the event waiting in the reorder buffer with its time on the reference clock
+type pendingEvent struct {
+	event Event
+	at    time.Time
+}
+
+// source is clock correction of a timing device
+type source struct {
+	offset   time.Duration
+	location *time.Location
+}
+
+// NewProcessor creates processor for the competition with given config.
+// Messages are logged in the language of config locale, unknown locale falls back to the default one
+func NewProcessor(config *config.Config, logger *zap.Logger) *Processor {
+	catalog, err := i18n.Get(config.Locale)
+	if err != nil {
+		logger.Warn("NewProcessor: falling back to default locale", zap.Error(err))
+		catalog, _ = i18n.Get(i18n.Default)
+	}
+	sources := make(map[string]source, len(config.Sources))
+	for name, sourceConfig := range config.Sources {
+		offset, err := sourceConfig.OffsetDuration()
+		if err != nil {
+			logger.Warn("NewProcessor: ignoring clock offset of source "+name, zap.Error(err))
+		}
+		location, err := sourceConfig.Location()
+		if err != nil {
+			logger.Warn("NewProcessor: ignoring time zone of source "+name, zap.Error(err))
+			location = time.UTC
+		}
+		sources[name] = source{offset: offset, location: location}
+	}
+	window, err := config.ReorderWindowDuration()
+	if err != nil {
+		logger.Warn("NewProcessor: reordering is disabled", zap.Error(err))
+	}
+	duplicateWindow, err := config.DuplicateWindowDuration()
+	if err != nil {
+		logger.Warn("NewProcessor: only exact duplicates are detected", zap.Error(err))
+	}
+	return &Processor{
+		config:      config,
+		logger:      logger,
+		catalog:     catalog,
+		competitors: make(map[int]*Competitor),
+		clock:       config.TimeReference(),
+		sources:     sources,
+		window:      window,
+
+		duplicateWindow: duplicateWindow,
+		seen:            make(map[string][]time.Time),
+		seenSeq:         make(map[string]bool),
+		awaitingStart:   make(map[int]bool),
+	}
+}
+
+// Competitors returns current state of all competitors
+func (p *Processor) Competitors() map[int]*Competitor {
+	return p.competitors
+}
+
+// Duplicates returns all dropped duplicate events
+func (p *Processor) Duplicates() []Event {
+	return p.duplicates
+}
+
+// OutgoingEvents returns all outgoing events generated so far
+func (p *Processor) OutgoingEvents() []Event {
+	return p.outgoingEvents
+}
+
+// Observe sets the function called with every accepted incoming event and every outgoing event in the order
+// they are logged. Rejected, duplicate and not yet reordered events are not passed
+func (p *Processor) Observe(observer func(event Event)) {
+	p.observer = observer
+}
+
+// notify passes the logged event to the observer
+func (p *Processor) notify(event Event) {
+	if p.observer != nil {
+		p.observer(event)
+	}
+}
+
+// warn logs the event which the processor failed to handle
+func (p *Processor) warn(event Event, message string, err error) {
+	p.logger.Warn(message, append(EventFields(event), zap.Error(err))...)
+}
+
+// emit registers and logs outgoing event, and appends it to the result of current Process call
+func (p *Processor) emit(outgoing []Event, event Event) []Event {
+	p.outgoingEvents = append(p.outgoingEvents, event)
+	LogEvent(p.logger, p.catalog, event)
+	p.notify(event)
+	return append(outgoing, event)
+}
+
+// eventTime returns time of the event on the reference clock: time of day is placed after the latest event,
+// then time zone and clock offset of the event source are applied
+func (p *Processor) eventTime(event Event) (time.Time, error) {
+	src, ok := p.sources[event.Source]
+	if !ok {
+		return timestamp.Resolve(event.Time, p.clock)
+	}
+	ref := p.clock
+	if !ref.IsZero() {
+		ref = ref.Add(src.offset)
+	}
+	t, err := timestamp.ResolveIn(event.Time, ref, src.location)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(-src.offset), nil
+}
+
+// Process applies single incoming event to the competition state and returns outgoing events caused by it.
+// With reorder window the event is held back and the returned events are caused by the events released from the buffer
+func (p *Processor) Process(event Event) []Event {
+	eventTime, err := p.eventTime(event)
+	if err != nil {
+		p.warn(event, "Process: error in event time format", err)
+		return nil
+	}
+	if eventType, ok := LookupEventType(event.EventID); ok {
+		if err = eventType.Validate(event); err != nil {
+			p.warn(event, "Process: invalid event", err)
+			return nil
+		}
+	}
+	if p.duplicate(event, eventTime) {
+		p.duplicates = append(p.duplicates, event)
+		p.logger.Warn("Process: duplicate event dropped", EventFields(event)...)
+		return nil
+	}
+
+	comp, exists := p.competitors[event.CompetitorID]
+	if !exists {
+		comp = &Competitor{
+			ID:          event.CompetitorID,
+			Hits:        make(map[int][]int),
+			Shots:       make(map[int]int),
+			Status:      "NotStarted",
+			CurrentLap:  -1,
+			LastLapTime: time.Time{},
+		}
+		p.competitors[event.CompetitorID] = comp
+	}
+	if p.clock.IsZero() || eventTime.After(p.clock) {
+		p.clock = eventTime.In(p.clock.Location())
+	}
+	if p.window == 0 {
+		return p.apply(comp, event, eventTime)
+	}
+	return p.reorder(event, eventTime)
+}
+
+// duplicate checks whether the event was already accepted and remembers it otherwise. Events with sequence number
+// are duplicates when the source already sent the number, other events when the same event came within the window
+func (p *Processor) duplicate(event Event, eventTime time.Time) bool {
+	if event.Seq != 0 {
+		key := fmt.Sprintf("%s#%d", event.Source, event.Seq)
+		if p.seenSeq[key] {
+			return true
+		}
+		p.seenSeq[key] = true
+		return false
+	}
+	key := fmt.Sprintf("%s %d %d %s", event.Source, event.EventID, event.CompetitorID, strings.Join(event.ExtraParams, " "))
+	for _, seen := range p.seen[key] {
+		if d := eventTime.Sub(seen); d <= p.duplicateWindow && d >= -p.duplicateWindow {
+			return true
+		}
+	}
+	p.seen[key] = append(p.seen[key], eventTime)
+	return false
+}
+
+// reorder puts the event into the reorder buffer and applies buffered events which are older than the window.
+// Event older than already applied ones came too late to be reordered, it is flagged and applied at once
+func (p *Processor) reorder(event Event, eventTime time.Time) []Event {
+	if !p.released.IsZero() && eventTime.Before(p.released) {
+		p.logger.Warn("Process: late event outside of reorder window", EventFields(event)...)
+		return p.apply(p.competitors[event.CompetitorID], event, eventTime)
+	}
+	i := sort.Search(len(p.pending), func(i int) bool {
+		return p.pending[i].at.After(eventTime)
+	})
+	p.pending = append(p.pending, pendingEvent{})
+	copy(p.pending[i+1:], p.pending[i:])
+	p.pending[i] = pendingEvent{event: event, at: eventTime}
+
+	var outgoing []Event
+	for len(p.pending) > 0 && !p.pending[0].at.After(p.clock.Add(-p.window)) {
+		outgoing = append(outgoing, p.release()...)
+	}
+	return outgoing
+}
+
+// release applies the oldest event of the reorder buffer
+func (p *Processor) release() []Event {
+	next := p.pending[0]
+	p.pending = p.pending[1:]
+	p.released = next.at
+	return p.apply(p.competitors[next.event.CompetitorID], next.event, next.at)
+}
+
+// Flush applies all events held in the reorder buffer, it is called at the end of the race
+func (p *Processor) Flush() []Event {
+	var outgoing []Event
+	for len(p.pending) > 0 {
+		outgoing = append(outgoing, p.release()...)
+	}
+	return outgoing
+}
+
+// validateRangeVisit checks that the firing range is the next one in the course order and it belongs to the current lap
+func (p *Processor) validateRangeVisit(comp *Competitor, rangeID int) error {
+	if comp.OnFiringRange {
+		return fmt.Errorf("competitor is already on firing range %d", comp.FiringRange)
+	}
+	if rangeID < 1 || rangeID > p.config.FiringLines {
+		return fmt.Errorf("firing range %d does not exist, ranges are 1-%d", rangeID, p.config.FiringLines)
+	}
+	if expected := len(comp.VisitedRanges) + 1; rangeID != expected {
+		return fmt.Errorf("expected firing range %d, got %d", expected, rangeID)
+	}
+	lap := comp.CurrentLap + 2
+	for _, r := range p.config.FiringRangesOnLap(lap) {
+		if r == rangeID {
+			return nil
 		}
+	}
+	return fmt.Errorf("firing range %d is not on lap %d", rangeID, lap)
+}
 
-		eventTime, err := time.Parse("15:04:05.000", event.Time)
+// validateHit checks that the competitor is on a firing range and the target exists and was not hit on this range
+func validateHit(comp *Competitor, target int) error {
+	if !comp.OnFiringRange {
+		return fmt.Errorf("competitor is not on a firing range")
+	}
+	if target < 1 || target > TargetsPerRange {
+		return fmt.Errorf("target %d does not exist, targets are 1-%d", target, TargetsPerRange)
+	}
+	for _, hit := range comp.Hits[comp.FiringRange] {
+		if hit == target {
+			return fmt.Errorf("target %d is already hit on firing range %d", target, comp.FiringRange)
+		}
+	}
+	return nil
+}
+
+// apply changes state of the competitor by the event and returns outgoing events caused by it
+func (p *Processor) apply(comp *Competitor, event Event, eventTime time.Time) []Event {
+	if event.EventID == EventOnStartLine || event.EventID == EventStarted {
+		// the competitor has come to the start, even late, and must not be raised as did not start by own event
+		delete(p.awaitingStart, comp.ID)
+	}
+	outgoing := p.checkNotStarted(eventTime, nil)
+	if !p.admit(comp, event) {
+		return outgoing
+	}
+	LogEvent(p.logger, p.catalog, event)
+	p.notify(event)
+
+	switch event.EventID {
+	case EventRegistered:
+		comp.Registered = true
+		comp.Status = "Registered"
+
+	case EventStartTimeDrawn:
+		startTime, err := timestamp.Resolve(event.ExtraParams[0], eventTime)
 		if err != nil {
-			log.Printf("Events: error in extraParams string format: %v", err)
-			continue
+			p.warn(event, "Process: error in extraParams string format", err)
+			return outgoing
+		}
+		comp.StartTime = startTime
+		if comp.GateTime.IsZero() && comp.ActualStart.IsZero() {
+			p.awaitingStart[comp.ID] = true
 		}
 
-		switch event.EventID {
-		case 1:
-			comp.Registered = true
-			comp.Status = "Registered"
-			LogEvent(event, "The competitor registered")
-
-		case 2:
-			startTime, err := time.Parse("15:04:05.000", event.ExtraParams[0])
-			if err != nil {
-				log.Printf("Events: error in extraParams string format: %v", err)
-				continue
-			}
-			comp.StartTime = startTime
-			LogEvent(event, fmt.Sprintf("The start time was set by a draw to %s", event.ExtraParams[0]))
-
-		case 3:
-			LogEvent(event, "The competitor is on the start line")
-
-		case 4:
-			comp.ActualStart = eventTime
-			comp.Status = "Started"
-			comp.LastLapTime = eventTime
-			startDelta, _ := time.ParseDuration(strings.Replace(config.StartDelta, ":", "h", 1) + "m" + "s")
-			if eventTime.Sub(comp.StartTime) > startDelta {
-				comp.Status = "NotStarted"
-				outgoingEvents = append(outgoingEvents, Event{
-					Time:         event.Time,
-					EventID:      32,
-					CompetitorID: comp.ID,
-				})
-				LogEvent(Event{Time: event.Time, EventID: 32, CompetitorID: comp.ID}, "The competitor is disqualified")
-			} else {
-				LogEvent(event, "The competitor has started")
-			}
+	case EventOnStartLine:
+		comp.GateTime = eventTime
+
+	case EventStarted:
+		comp.ActualStart = eventTime
+		comp.Status = "Started"
+		comp.TimingStart = p.timingStart(comp, event)
+		comp.LastLapTime = comp.TimingStart
+		comp.LastLapLine = event.Line
+		outgoing = p.checkStart(comp, event, eventTime, outgoing)
+
+	case EventOnFiringRange:
+		rangeID, _ := intParam(event)
+		comp.FiringRange = rangeID
+		comp.OnFiringRange = true
+		comp.VisitedRanges = append(comp.VisitedRanges, rangeID)
+		comp.Shots[comp.FiringRange] = TargetsPerRange
+		comp.ShotLines = append(comp.ShotLines, event.Line)
+
+	case EventTargetHit:
+		target, _ := intParam(event)
+		comp.Hits[comp.FiringRange] = append(comp.Hits[comp.FiringRange], target)
+		comp.HitLines = append(comp.HitLines, event.Line)
+
+	case EventLeftFiringRange:
+		comp.OnFiringRange = false
+		misses := comp.Shots[comp.FiringRange] - len(comp.Hits[comp.FiringRange])
+		comp.PenaltyLaps += misses
+
+	case EventEnteredPenalty:
+		comp.LastPenaltyTime = eventTime
+		comp.LastPenaltyLine = event.Line
+		comp.InPenalty = true
+
+	case EventLeftPenalty:
+		penaltyTime := eventTime.Sub(comp.LastPenaltyTime)
+		comp.PenaltyTimes = append(comp.PenaltyTimes, penaltyTime)
+		comp.PenaltyLines = append(comp.PenaltyLines, [2]int{comp.LastPenaltyLine, event.Line})
+		comp.PenaltyLapsServed += comp.PenaltyLaps
+		comp.PenaltyLaps = 0
+		comp.InPenalty = false
+
+	case EventLapEnded:
+		comp.CurrentLap++
+		lapTime := eventTime.Sub(comp.LastLapTime)
+		comp.LapTimes = append(comp.LapTimes, lapTime)
+		comp.LapLines = append(comp.LapLines, [2]int{comp.LastLapLine, event.Line})
+		comp.LastLapTime = eventTime
+		comp.LastLapLine = event.Line
+
+	case EventCannotContinue:
+		comp.Status = "NotFinished"
+	}
 
-		case 5:
-			var rangeID int
-			_, err := fmt.Sscanf(event.ExtraParams[0], "%d", &rangeID)
-			if err != nil {
-				log.Printf("Events: error in extraParams string format: %v", err)
-				continue
-			}
-			comp.FiringRange = rangeID
-			comp.Shots[comp.FiringRange] = 5
-			LogEvent(event, fmt.Sprintf("The competitor is on the firing range(%d)", rangeID))
-
-		case 6:
-			var target int
-			_, err := fmt.Sscanf(event.ExtraParams[0], "%d", &target)
-			if err != nil {
-				log.Printf("Events: error in extraParams string format: %v", err)
-				continue
-			}
-			comp.Hits[comp.FiringRange] = append(comp.Hits[comp.FiringRange], target)
-			LogEvent(event, fmt.Sprintf("The target(%d) has been hit by competitor", target))
-
-		case 7:
-			misses := comp.Shots[comp.FiringRange] - len(comp.Hits[comp.FiringRange])
-			comp.PenaltyLaps += misses
-			LogEvent(event, "The competitor left the firing range")
-
-		case 8:
-			comp.LastPenaltyTime = eventTime
-			LogEvent(event, "The competitor entered the penalty laps")
-
-		case 9:
-			penaltyTime := eventTime.Sub(comp.LastPenaltyTime)
-			comp.PenaltyTimes = append(comp.PenaltyTimes, penaltyTime)
-			comp.PenaltyLaps--
-			LogEvent(event, "The competitor left the penalty laps")
-
-		case 10:
-			comp.CurrentLap++
-			var lapTime time.Duration
-			if comp.CurrentLap == 0 {
-				lapTime = eventTime.Sub(comp.ActualStart)
-			} else {
-				lapTime = eventTime.Sub(comp.LastLapTime)
-			}
-			comp.LapTimes = append(comp.LapTimes, lapTime)
-			comp.LastLapTime = eventTime
-			LogEvent(event, fmt.Sprintf("The competitor ended the main lap %d", comp.CurrentLap+1))
-			if comp.CurrentLap+1 == config.Laps && comp.PenaltyLaps == 0 {
-				comp.Status = "Finished"
-				outgoingEvents = append(outgoingEvents, Event{
-					Time:         event.Time,
-					EventID:      33,
-					CompetitorID: comp.ID,
-				})
-				LogEvent(Event{Time: event.Time, EventID: 33, CompetitorID: comp.ID}, "The competitor has finished")
-			}
+	comp.Trace = append(comp.Trace, TraceEntry{Event: event, Time: eventTime})
+	if event.EventID == EventLapEnded && comp.CurrentLap+1 == p.config.Laps && comp.Status == "Started" {
+		outgoing = p.finish(comp, event, outgoing)
+	}
+	comp.Prediction = Predict(p.config, comp)
+	return outgoing
+}
 
-		case 11:
-			comp.Status = "NotFinished"
-			event.Time = eventTime.Format("15:04:05.000")
-			LogEvent(event, fmt.Sprintf("The competitor can't continue: %s", strings.Join(event.ExtraParams, " ")))
+// admit checks that the event is possible in the current state of the competitor. A rejected event is only
+// warned about, it is neither logged as happened nor applied
+func (p *Processor) admit(comp *Competitor, event Event) bool {
+	var message string
+	var err error
+	switch event.EventID {
+	case EventOnFiringRange, EventTargetHit:
+		var param int
+		if param, err = intParam(event); err != nil {
+			message = "Process: error in extraParams string format"
+		} else if event.EventID == EventOnFiringRange {
+			message, err = "Process: firing range visit rejected", p.validateRangeVisit(comp, param)
+		} else {
+			message, err = "Process: hit rejected", validateHit(comp, param)
+		}
+	case EventLeftFiringRange:
+		if !comp.OnFiringRange {
+			message, err = "Process: leaving firing range rejected", fmt.Errorf("competitor is not on a firing range")
+		}
+	case EventEnteredPenalty:
+		message = "Process: entering penalty laps rejected"
+		if comp.InPenalty {
+			err = fmt.Errorf("competitor is already on penalty laps")
+		} else if comp.PenaltyLaps == 0 {
+			err = fmt.Errorf("competitor has no penalty laps to run")
+		}
+	case EventLeftPenalty:
+		if !comp.InPenalty {
+			message, err = "Process: leaving penalty laps rejected", fmt.Errorf("competitor is not on penalty laps")
+		}
+	}
+	if err != nil {
+		p.warn(event, message, err)
+		return false
+	}
+	return true
+}
+
+// intParam parses the integer parameter of the event, such as firing range or target
+func intParam(event Event) (int, error) {
+	var param int
+	if _, err := fmt.Sscanf(event.ExtraParams[0], "%d", &param); err != nil {
+		return 0, err
+	}
+	return param, nil
+}
+
+// finish validates the race trace of the competitor after the last lap and either finishes the competitor
+// or rejects the finish with the reason shown in the report
+func (p *Processor) finish(comp *Competitor, event Event, outgoing []Event) []Event {
+	if err := validateTrace(p.config.FiringLines, comp.Trace); err != nil {
+		p.warn(event, "Process: finish rejected", err)
+		comp.Status = "NotFinished"
+		var finishErr *FinishError
+		if errors.As(err, &finishErr) {
+			comp.FinishRejection = finishErr.Reason
+		}
+		return outgoing
+	}
+	comp.Status = "Finished"
+	return p.emit(outgoing, Event{
+		Time:         event.Time,
+		EventID:      EventFinished,
+		CompetitorID: comp.ID,
+	})
+}
+
+// Events generate map of competitors and slice of outgoing events, messages are logged with global zap logger
+func Events(config *config.Config, events []Event) (map[int]*Competitor, []Event) {
+	processor := NewProcessor(config, zap.L())
+	for _, event := range events {
+		processor.Process(event)
+	}
+	processor.Flush()
+	return processor.Competitors(), processor.OutgoingEvents()
+}
+
+// StateAt processes events up to the moment and returns the state of competitors at it. Events after the moment
+// are skipped, events held for reordering are applied and outgoing events due by the moment, such as did not start,
+// are raised. Messages are not logged
+func StateAt(config *config.Config, events []Event, at time.Time) map[int]*Competitor {
+	processor := NewProcessor(config, zap.NewNop())
+	for _, event := range events {
+		if eventTime, err := processor.eventTime(event); err == nil && eventTime.After(at) {
+			continue
 		}
+		processor.Process(event)
 	}
-	return competitors, outgoingEvents
+	processor.Flush()
+	processor.Tick(at)
+	return processor.Competitors()
 }
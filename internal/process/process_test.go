@@ -2,11 +2,17 @@ package process
 
 import (
 	"TelecomTask/internal/config"
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/timestamp"
+	"errors"
 	"math"
 	"os"
 	"reflect"
 	"testing"
 	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // Helper function to compare slices of strings
@@ -50,25 +56,29 @@ func mapsEqualIntSlice(a, b map[int][]int) bool {
 	return true
 }
 
-// TestParseEvent tests the parseEvent function with various inputs
+// TestParseEvent tests the ParseEvent function with various inputs
 func TestParseEvent(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected Event
 		err      bool
 	}{
-		{"[09:05:59.867] 1 1", Event{"09:05:59.867", 1, 1, []string{}}, false},
-		{"[09:15:00.841] 2 1 09:30:00.000", Event{"09:15:00.841", 2, 1, []string{"09:30:00.000"}}, false},
+		{"[09:05:59.867] 1 1", Event{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: []string{}}, false},
+		{"[09:15:00.841] 2 1 09:30:00.000", Event{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: []string{"09:30:00.000"}}, false},
 		{"invalid line", Event{}, true},
-		{"[12:34:56.789] 3 2 param1 param2", Event{"12:34:56.789", 3, 2, []string{"param1", "param2"}}, false},
+		{"[12:34:56.789] 3 2 param1 param2", Event{Time: "12:34:56.789", EventID: 3, CompetitorID: 2, ExtraParams: []string{"param1", "param2"}}, false},
+		{"[10:00:00.000] src=finish 10 1", Event{Time: "10:00:00.000", EventID: 10, CompetitorID: 1, ExtraParams: []string{}, Source: "finish"}, false},
+		{"[10:00:00.000] src=finish seq=42 10 1", Event{Time: "10:00:00.000", EventID: 10, CompetitorID: 1, ExtraParams: []string{}, Source: "finish", Seq: 42}, false},
+		{"[10:00:00.000] seq=x 10 1", Event{}, true},
+		{"[10:00:00.000] foo=bar 10 1", Event{}, true},
 	}
 	for _, test := range tests {
-		event, err := parseEvent(test.input)
+		event, err := ParseEvent(test.input)
 		if test.err && err == nil {
 			t.Errorf("Expected error for input %s", test.input)
 		} else if !test.err && err != nil {
 			t.Errorf("Unexpected error for input %s: %v", test.input, err)
-		} else if !test.err && (event.Time != test.expected.Time || event.EventID != test.expected.EventID || event.CompetitorID != test.expected.CompetitorID || !stringSlicesEqual(event.ExtraParams, test.expected.ExtraParams)) {
+		} else if !test.err && (event.Time != test.expected.Time || event.EventID != test.expected.EventID || event.CompetitorID != test.expected.CompetitorID || !stringSlicesEqual(event.ExtraParams, test.expected.ExtraParams) || event.Source != test.expected.Source || event.Seq != test.expected.Seq) {
 			t.Errorf("For input %s, expected %v, got %v", test.input, test.expected, event)
 		}
 	}
@@ -87,7 +97,7 @@ func TestLoadEvents(t *testing.T) {
 		}
 	}(tmpfile.Name())
 
-	testEvents := "[09:05:59.867] 1 1\n[09:15:00.841] 2 1 09:30:00.000\n[12:34:56.789] 3 2 param1 param2"
+	testEvents := "[09:05:59.867] 1 1\n[09:15:00.841] 2 1 09:30:00.000\n[12:34:56.789] 3 2 param1 param2\n[12:35:00.000] 99 2"
 	if _, err := tmpfile.Write([]byte(testEvents)); err != nil {
 		t.Fatal(err)
 	}
@@ -126,12 +136,17 @@ func TestFormatDuration(t *testing.T) {
 		{1*time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond, "01:02:03.004"},
 		{9*time.Minute + 48*time.Second, "00:09:48.000"},
 		{1 * time.Minute, "00:01:00.000"},
+		{26*time.Hour + 5*time.Millisecond, "26:00:00.005"},
 	}
 	for _, test := range tests {
-		result := formatDuration(test.input)
+		result := FormatDuration(test.input)
 		if result != test.expected {
 			t.Errorf("For input %v, expected %s, got %s", test.input, test.expected, result)
 		}
+		parsed, err := ParseDuration(result)
+		if err != nil || parsed != test.input {
+			t.Errorf("ParseDuration(%s): expected %v, got %v, %v", result, test.input, parsed, err)
+		}
 	}
 }
 
@@ -147,23 +162,23 @@ func TestEvents(t *testing.T) {
 	}
 
 	events := []Event{
-		{"10:00:00.000", 1, 1, []string{}},
-		{"10:00:05.000", 2, 1, []string{"10:00:10.000"}},
-		{"10:00:08.000", 3, 1, []string{}},
-		{"10:00:12.000", 4, 1, []string{}},
-		{"10:05:00.000", 5, 1, []string{"1"}},
-		{"10:05:10.000", 6, 1, []string{"1"}},
-		{"10:05:20.000", 6, 1, []string{"2"}},
-		{"10:05:30.000", 6, 1, []string{"3"}},
-		{"10:05:40.000", 6, 1, []string{"4"}},
-		{"10:05:50.000", 7, 1, []string{}},
-		{"10:06:00.000", 8, 1, []string{}},
-		{"10:07:00.000", 9, 1, []string{}},
-		{"10:10:00.000", 10, 1, []string{}},
-		{"10:30:05.000", 11, 1, []string{"Injury"}},
-	}
-
-	competitors, _ := Events(cfg, events)
+		{Time: "10:00:00.000", EventID: 1, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:00:05.000", EventID: 2, CompetitorID: 1, ExtraParams: []string{"10:00:10.000"}},
+		{Time: "10:00:08.000", EventID: 3, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:00:12.000", EventID: 4, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:05:00.000", EventID: 5, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:05:10.000", EventID: 6, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:05:20.000", EventID: 6, CompetitorID: 1, ExtraParams: []string{"2"}},
+		{Time: "10:05:30.000", EventID: 6, CompetitorID: 1, ExtraParams: []string{"3"}},
+		{Time: "10:05:40.000", EventID: 6, CompetitorID: 1, ExtraParams: []string{"4"}},
+		{Time: "10:05:50.000", EventID: 7, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:06:00.000", EventID: 8, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:07:00.000", EventID: 9, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:10:00.000", EventID: 10, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:30:05.000", EventID: 11, CompetitorID: 1, ExtraParams: []string{"Injury"}},
+	}
+
+	competitors, outgoing := Events(cfg, events)
 
 	if len(competitors) != 1 {
 		t.Errorf("Expected 1 competitor, got %d", len(competitors))
@@ -206,6 +221,48 @@ func TestEvents(t *testing.T) {
 		t.Errorf("Expected shots %v, got %v", expectedShots, comp.Shots)
 	}
 
+	if len(outgoing) != 0 {
+		t.Errorf("Expected start within start delta to raise no events, got %v", outgoing)
+	}
+
+}
+
+// TestPenaltyVisit tests that one penalty loop visit serves all penalty laps pending from the firing range
+func TestPenaltyVisit(t *testing.T) {
+	cfg := &config.Config{
+		Laps:        1,
+		LapLen:      1000,
+		PenaltyLen:  100,
+		FiringLines: 1,
+		Start:       "10:00:00",
+		StartDelta:  "00:00:10",
+	}
+
+	events := []Event{
+		{Time: "10:00:00.000", EventID: 1, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:00:05.000", EventID: 2, CompetitorID: 1, ExtraParams: []string{"10:00:10.000"}},
+		{Time: "10:00:12.000", EventID: 4, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:05:00.000", EventID: 5, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:05:10.000", EventID: 6, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:05:20.000", EventID: 6, CompetitorID: 1, ExtraParams: []string{"2"}},
+		{Time: "10:05:30.000", EventID: 6, CompetitorID: 1, ExtraParams: []string{"3"}},
+		{Time: "10:05:50.000", EventID: 7, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:06:00.000", EventID: 8, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:07:00.000", EventID: 9, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:10:00.000", EventID: 10, CompetitorID: 1, ExtraParams: []string{}},
+	}
+
+	competitors, _ := Events(cfg, events)
+	comp := competitors[1]
+	if comp.PenaltyLaps != 0 || comp.PenaltyLapsServed != 2 {
+		t.Errorf("Expected the visit to serve 2 penalty laps, got %d pending and %d served", comp.PenaltyLaps, comp.PenaltyLapsServed)
+	}
+	if comp.Status != "Finished" {
+		t.Errorf("Expected status Finished, got %s", comp.Status)
+	}
+	if speed := GenerateReport(competitors, cfg)[0].PenaltySpeed; speed != float64(2*100)/60 {
+		t.Errorf("Expected penalty speed of 2 laps in 1m, got %v", speed)
+	}
 }
 
 // TestGenerateReport tests generating the final report
@@ -227,6 +284,8 @@ func TestGenerateReport(t *testing.T) {
 			PenaltyTimes: []time.Duration{1 * time.Minute},
 			Hits:         map[int][]int{1: {1, 2, 3, 4}},
 			Shots:        map[int]int{1: 5},
+
+			PenaltyLapsServed: 1,
 		},
 	}
 
@@ -277,3 +336,638 @@ func TestGenerateReport(t *testing.T) {
 		t.Errorf("Expected HitsShots 4/5, got %s", report.HitsShots)
 	}
 }
+
+// TestParseEventValidation tests that events are validated against the event catalog
+func TestParseEventValidation(t *testing.T) {
+	tests := []struct {
+		input string
+		err   bool
+	}{
+		{"[09:05:59.867] 12 1", true},
+		{"[09:05:59.867] 33 1", true},
+		{"[09:15:00.841] 2 1", true},
+		{"[09:15:00.841] 2 1 tomorrow", true},
+		{"[10:08:49.289] 5 1 first", true},
+		{"[10:08:49.289] 5 1 1", false},
+		{"[10:30:05.000] 11 1", false},
+		{"[10:30:05.000] 11 1 Lost in the forest", false},
+	}
+	for _, test := range tests {
+		_, err := ParseEvent(test.input)
+		if test.err && err == nil {
+			t.Errorf("Expected error for input %s", test.input)
+		} else if !test.err && err != nil {
+			t.Errorf("Unexpected error for input %s: %v", test.input, err)
+		}
+	}
+}
+
+// TestEventMessage tests messages built from the event catalog
+func TestEventMessage(t *testing.T) {
+	tests := []struct {
+		event    Event
+		expected string
+	}{
+		{Event{Time: "09:05:59.867", EventID: EventRegistered, CompetitorID: 1, ExtraParams: []string{}}, "The competitor(1) registered"},
+		{Event{Time: "09:15:00.841", EventID: EventStartTimeDrawn, CompetitorID: 1, ExtraParams: []string{"09:30:00.000"}}, "The start time for competitor(1) was set by a draw to 09:30:00.000"},
+		{Event{Time: "10:08:50.884", EventID: EventTargetHit, CompetitorID: 2, ExtraParams: []string{"4"}}, "The target(4) has been hit by competitor(2)"},
+		{Event{Time: "10:30:05.000", EventID: EventCannotContinue, CompetitorID: 3, ExtraParams: []string{"Lost", "in", "the", "forest"}}, "The competitor(3) can't continue: Lost in the forest"},
+		{Event{Time: "10:30:05.000", EventID: EventFinished, CompetitorID: 3, ExtraParams: nil}, "The competitor(3) has finished"},
+		{Event{Time: "10:30:05.000", EventID: 99, CompetitorID: 3, ExtraParams: nil}, "Unknown event(99) of competitor(3)"},
+	}
+	for _, test := range tests {
+		if message := test.event.Message(); message != test.expected {
+			t.Errorf("For event %v, expected %q, got %q", test.event, test.expected, message)
+		}
+	}
+}
+
+// TestLocalizedMessage tests that every event type is translated by every locale
+func TestLocalizedMessage(t *testing.T) {
+	ru, err := i18n.Get("ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id := range eventTypes {
+		if _, ok := ru.Events[id]; !ok {
+			t.Errorf("Event %d has no russian message", id)
+		}
+	}
+	event := Event{Time: "10:08:50.884", EventID: EventTargetHit, CompetitorID: 2, ExtraParams: []string{"4"}}
+	if message := event.LocalizedMessage(ru); message != "Мишень(4) поражена участником(2)" {
+		t.Errorf("Unexpected russian message: %s", message)
+	}
+	drawn := Event{Time: "09:15:00.841", EventID: EventStartTimeDrawn, CompetitorID: 1, ExtraParams: []string{"09:30:00.000"}}
+	if message := drawn.LocalizedMessage(ru); message != "Время старта участника(1) определено жеребьевкой: 09:30:00,000" {
+		t.Errorf("Unexpected russian message: %s", message)
+	}
+}
+
+// TestClockSources tests that times of every timing device are corrected by its clock offset and time zone
+func TestClockSources(t *testing.T) {
+	cfg := &config.Config{
+		Laps:        1,
+		LapLen:      1000,
+		PenaltyLen:  100,
+		FiringLines: 1,
+		Start:       "2025-01-12T10:00:00.000Z",
+		StartDelta:  "00:00:30",
+		Sources: map[string]config.SourceConfig{
+			"gate":   {Offset: "2s"},
+			"finish": {Offset: "-500ms", TimeZone: "Europe/Moscow"},
+		},
+	}
+	events := []Event{
+		{Time: "09:00:00.000", EventID: EventRegistered, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "09:30:00.000", EventID: EventStartTimeDrawn, CompetitorID: 1, ExtraParams: []string{"10:00:00.000"}},
+		{Time: "10:00:02.000", EventID: EventStarted, CompetitorID: 1, ExtraParams: []string{}, Source: "gate"},
+		{Time: "2025-01-12T13:10:00.000", EventID: EventLapEnded, CompetitorID: 1, ExtraParams: []string{}, Source: "finish"},
+	}
+	competitors, _ := Events(cfg, events)
+	comp := competitors[1]
+	if !comp.ActualStart.Equal(time.Date(2025, 1, 12, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected start corrected by gate offset, got %v", comp.ActualStart)
+	}
+	if len(comp.LapTimes) != 1 || comp.LapTimes[0] != 10*time.Minute+500*time.Millisecond {
+		t.Errorf("Expected lap time corrected by finish offset and zone, got %v", comp.LapTimes)
+	}
+}
+
+// TestClockSourcesMixed tests that events without source are not moved to the zone of the previous sourced event
+func TestClockSourcesMixed(t *testing.T) {
+	cfg := &config.Config{
+		Laps:        3,
+		LapLen:      1000,
+		PenaltyLen:  100,
+		FiringLines: 1,
+		Start:       "2025-01-12T10:00:00.000Z",
+		StartDelta:  "00:00:30",
+		Sources: map[string]config.SourceConfig{
+			"finish": {TimeZone: "Europe/Moscow"},
+		},
+	}
+	events := []Event{
+		{Time: "09:00:00.000", EventID: EventRegistered, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "09:30:00.000", EventID: EventStartTimeDrawn, CompetitorID: 1, ExtraParams: []string{"10:00:00.000"}},
+		{Time: "10:00:00.000", EventID: EventStarted, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:05:00.000", EventID: EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:05:01.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:05:02.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"2"}},
+		{Time: "10:05:03.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"3"}},
+		{Time: "10:05:04.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"4"}},
+		{Time: "10:05:05.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"5"}},
+		{Time: "10:05:10.000", EventID: EventLeftFiringRange, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "13:10:00.000", EventID: EventLapEnded, CompetitorID: 1, ExtraParams: []string{}, Source: "finish"},
+		{Time: "10:20:00.000", EventID: EventLapEnded, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "13:30:00.000", EventID: EventLapEnded, CompetitorID: 1, ExtraParams: []string{}, Source: "finish"},
+	}
+	competitors, _ := Events(cfg, events)
+	comp := competitors[1]
+	expected := []time.Duration{10 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+	if !reflect.DeepEqual(comp.LapTimes, expected) {
+		t.Errorf("Expected lap times %v, got %v", expected, comp.LapTimes)
+	}
+	if comp.Status != "Finished" {
+		t.Errorf("Expected Finished, got %s", comp.Status)
+	}
+}
+
+// TestReorderWindow tests that events are sorted within the reorder window and late events are flagged
+func TestReorderWindow(t *testing.T) {
+	processor, logs := newTestProcessor(t, func(cfg *config.Config) {
+		cfg.Laps = 1
+		cfg.ReorderWindow = "5s"
+	})
+	events := []Event{
+		{Time: "09:00:00.000", EventID: EventRegistered, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:10:00.000", EventID: EventLapEnded, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:09:57.000", EventID: EventStarted, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:10:10.000", EventID: EventRegistered, CompetitorID: 2, ExtraParams: []string{}},
+		{Time: "10:09:00.000", EventID: EventOnStartLine, CompetitorID: 1, ExtraParams: []string{}},
+	}
+	for _, event := range events {
+		processor.Process(event)
+	}
+	if processor.Competitors()[2].Registered {
+		t.Error("Expected the latest event to be held back in the reorder buffer")
+	}
+	processor.Tick(mustResolve(t, "10:10:14.000"))
+	if processor.Competitors()[2].Registered {
+		t.Error("Expected the latest event to be held back until the window passes")
+	}
+	processor.Tick(mustResolve(t, "10:10:15.000"))
+
+	comp := processor.Competitors()[1]
+	if len(comp.LapTimes) != 1 || comp.LapTimes[0] != 3*time.Second {
+		t.Errorf("Expected lap time of reordered events, got %v", comp.LapTimes)
+	}
+	if !processor.Competitors()[2].Registered {
+		t.Error("Expected buffered event to be applied by the clock tick")
+	}
+	late := logs.FilterMessage("Process: late event outside of reorder window").All()
+	if len(late) != 1 || late[0].ContextMap()["event_id"] != int64(EventOnStartLine) {
+		t.Errorf("Expected the late event to be flagged, got %v", late)
+	}
+}
+
+// TestDuplicates tests that exact duplicates, near duplicates and repeated sequence numbers are dropped
+func TestDuplicates(t *testing.T) {
+	processor, logs := newTestProcessor(t, func(cfg *config.Config) {
+		cfg.FiringLines = 2
+		cfg.DuplicateWindow = "1s"
+	})
+	events := []Event{
+		{Time: "10:00:00.000", EventID: EventStarted, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:01:00.000", EventID: EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:01:10.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:01:10.500", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:01:30.000", EventID: EventLeftFiringRange, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:05:00.000", EventID: EventLapEnded, CompetitorID: 1, ExtraParams: []string{}, Source: "finish", Seq: 7},
+		{Time: "10:05:00.000", EventID: EventLapEnded, CompetitorID: 1, ExtraParams: []string{}, Source: "finish", Seq: 7},
+		{Time: "10:05:00.000", EventID: EventLapEnded, CompetitorID: 2, ExtraParams: []string{}},
+		{Time: "10:05:00.000", EventID: EventLapEnded, CompetitorID: 2, ExtraParams: []string{}},
+		{Time: "10:06:00.000", EventID: EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"2"}},
+		{Time: "10:06:10.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:06:20.000", EventID: EventRegistered, CompetitorID: 3, ExtraParams: []string{}, Source: "finish", Seq: 7},
+	}
+	for _, event := range events {
+		processor.Process(event)
+	}
+
+	comp := processor.Competitors()[1]
+	if len(comp.Hits[1]) != 1 || len(comp.Hits[2]) != 1 {
+		t.Errorf("Expected a hit on every range, got %v", comp.Hits)
+	}
+	if len(comp.LapTimes) != 1 || len(processor.Competitors()[2].LapTimes) != 1 {
+		t.Errorf("Expected a lap of every competitor, got %v and %v", comp.LapTimes, processor.Competitors()[2].LapTimes)
+	}
+	expected := []Event{events[3], events[6], events[8], events[11]}
+	if !reflect.DeepEqual(processor.Duplicates(), expected) {
+		t.Errorf("Expected duplicates %v, got %v", expected, processor.Duplicates())
+	}
+	if n := logs.FilterMessage("Process: duplicate event dropped").Len(); n != 4 {
+		t.Errorf("Expected 4 reported duplicates, got %d", n)
+	}
+	if _, ok := processor.Competitors()[3]; ok {
+		t.Error("Expected no competitor created by a dropped duplicate")
+	}
+}
+
+// TestHitValidation tests that hits outside of a firing range, of unknown targets and repeated hits are rejected
+func TestHitValidation(t *testing.T) {
+	processor, logs := newTestProcessor(t, func(cfg *config.Config) {
+		cfg.Laps = 1
+	})
+	events := []Event{
+		{Time: "10:00:00.000", EventID: EventStarted, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:00:50.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:01:00.000", EventID: EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:01:10.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:01:11.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"6"}},
+		{Time: "10:01:12.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"0"}},
+		{Time: "10:01:13.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:01:14.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"5"}},
+		{Time: "10:01:30.000", EventID: EventLeftFiringRange, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:01:40.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"2"}},
+	}
+	for _, event := range events {
+		processor.Process(event)
+	}
+
+	comp := processor.Competitors()[1]
+	if !reflect.DeepEqual(comp.Hits[1], []int{1, 5}) {
+		t.Errorf("Expected hits [1 5], got %v", comp.Hits[1])
+	}
+	if comp.PenaltyLaps != 3 {
+		t.Errorf("Expected 3 penalty laps, got %d", comp.PenaltyLaps)
+	}
+	if n := logs.FilterMessage("Process: hit rejected").Len(); n != 5 {
+		t.Errorf("Expected 5 rejected hits, got %d", n)
+	}
+	if n := logs.FilterLevelExact(zap.InfoLevel).FilterField(zap.Int("event_id", EventTargetHit)).Len(); n != 2 {
+		t.Errorf("Expected only 2 accepted hits to be logged, got %d", n)
+	}
+}
+
+// TestRangeVisits tests that firing ranges are visited in the course order, each on its lap, before the finish
+func TestRangeVisits(t *testing.T) {
+	processor, logs := newTestProcessor(t, func(cfg *config.Config) {
+		cfg.FiringLines = 2
+	})
+	events := []Event{
+		{Time: "10:00:00.000", EventID: EventStarted, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:01:00.000", EventID: EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"7"}},
+		{Time: "10:01:01.000", EventID: EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"2"}},
+		{Time: "10:01:02.000", EventID: EventLeftFiringRange, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:01:03.000", EventID: EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:01:04.000", EventID: EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"1"}},
+	}
+	for _, target := range []string{"1", "2", "3", "4", "5"} {
+		events = append(events, Event{Time: "10:01:10.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{target}})
+	}
+	events = append(events,
+		Event{Time: "10:01:30.000", EventID: EventLeftFiringRange, CompetitorID: 1, ExtraParams: []string{}},
+		Event{Time: "10:01:40.000", EventID: EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"1"}},
+		Event{Time: "10:05:00.000", EventID: EventLapEnded, CompetitorID: 1, ExtraParams: []string{}},
+		Event{Time: "10:10:00.000", EventID: EventLapEnded, CompetitorID: 1, ExtraParams: []string{}},
+	)
+	for _, event := range events {
+		processor.Process(event)
+	}
+
+	comp := processor.Competitors()[1]
+	if !reflect.DeepEqual(comp.VisitedRanges, []int{1}) {
+		t.Errorf("Expected visited ranges [1], got %v", comp.VisitedRanges)
+	}
+	if n := logs.FilterMessage("Process: firing range visit rejected").Len(); n != 4 {
+		t.Errorf("Expected 4 rejected visits, got %d", n)
+	}
+	if n := logs.FilterMessage("Process: leaving firing range rejected").Len(); n != 1 {
+		t.Errorf("Expected 1 rejected leaving, got %d", n)
+	}
+	if comp.Status == "Finished" || logs.FilterMessage("Process: finish rejected").Len() != 1 {
+		t.Errorf("Expected finish to be rejected without the second range, got status %s", comp.Status)
+	}
+	if n := logs.FilterLevelExact(zap.InfoLevel).FilterField(zap.Int("event_id", EventOnFiringRange)).Len(); n != 1 {
+		t.Errorf("Expected only the accepted visit to be logged, got %d", n)
+	}
+}
+
+// TestValidateTrace tests course rules checked before the finish
+func TestValidateTrace(t *testing.T) {
+	at := func(event int, minute int) TraceEntry {
+		return TraceEntry{Event: Event{EventID: event}, Time: time.Date(0, 1, 1, 10, minute, 0, 0, time.UTC)}
+	}
+	shooting := func(minute int) []TraceEntry {
+		trace := []TraceEntry{at(EventOnFiringRange, minute)}
+		for i := 0; i < TargetsPerRange; i++ {
+			trace = append(trace, at(EventTargetHit, minute))
+		}
+		return append(trace, at(EventLeftFiringRange, minute))
+	}
+	tests := []struct {
+		name   string
+		trace  []TraceEntry
+		reason string
+	}{
+		{"valid", append(append([]TraceEntry{at(EventStarted, 0)}, shooting(5)...), at(EventLapEnded, 10)), ""},
+		{"negative lap", append(append([]TraceEntry{at(EventStarted, 20)}, shooting(5)...), at(EventLapEnded, 10)), ReasonNegativeDuration},
+		{"penalty not entered", []TraceEntry{at(EventStarted, 0), at(EventLeftPenalty, 5), at(EventLapEnded, 10)}, ReasonPenaltyNotEntered},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateTrace(1, test.trace)
+			reason := ""
+			var finishErr *FinishError
+			if errors.As(err, &finishErr) {
+				reason = finishErr.Reason
+			}
+			if reason != test.reason {
+				t.Errorf("Expected reason %q, got %v", test.reason, err)
+			}
+		})
+	}
+}
+
+// TestPenaltyValidation tests that penalty laps are entered only with pending penalty laps and not twice
+func TestPenaltyValidation(t *testing.T) {
+	processor, logs := newTestProcessor(t, func(cfg *config.Config) {
+		cfg.Laps = 1
+	})
+	events := []Event{
+		{Time: "10:00:00.000", EventID: EventStarted, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:00:30.000", EventID: EventEnteredPenalty, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:01:00.000", EventID: EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:01:10.000", EventID: EventTargetHit, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "10:01:30.000", EventID: EventLeftFiringRange, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:02:00.000", EventID: EventEnteredPenalty, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:02:10.000", EventID: EventEnteredPenalty, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:03:00.000", EventID: EventLeftPenalty, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:03:10.000", EventID: EventEnteredPenalty, CompetitorID: 1, ExtraParams: []string{}},
+	}
+	for _, event := range events {
+		processor.Process(event)
+	}
+
+	comp := processor.Competitors()[1]
+	if !reflect.DeepEqual(comp.PenaltyTimes, []time.Duration{time.Minute}) || comp.PenaltyLapsServed != 4 {
+		t.Errorf("Expected one penalty visit of 4 laps in 1m, got %v of %d laps", comp.PenaltyTimes, comp.PenaltyLapsServed)
+	}
+	if n := logs.FilterMessage("Process: entering penalty laps rejected").Len(); n != 3 {
+		t.Errorf("Expected 3 rejected penalty entries, got %d", n)
+	}
+	if n := logs.FilterLevelExact(zap.InfoLevel).FilterField(zap.Int("event_id", EventEnteredPenalty)).Len(); n != 1 {
+		t.Errorf("Expected only the accepted penalty entry to be logged, got %d", n)
+	}
+}
+
+// TestStartPolicies tests outcomes of late and early starts under every policy
+func TestStartPolicies(t *testing.T) {
+	penalty := config.StartPolicy{Action: config.StartPenalty, Penalty: "1m"}
+	tests := []struct {
+		name         string
+		start        string
+		late, early  config.StartPolicy
+		status       string
+		outcome      string
+		startPenalty time.Duration
+		lapTime      time.Duration
+	}{
+		{"in time", "10:00:20.000", config.StartPolicy{}, config.StartPolicy{}, "Started", "", 0, 10 * time.Minute},
+		{"late default", "10:01:00.000", config.StartPolicy{}, config.StartPolicy{}, "NotStarted", StartLateDisqualified, 0, 9*time.Minute + 20*time.Second},
+		{"late penalty", "10:01:00.000", penalty, config.StartPolicy{}, "Started", StartLatePenalty, time.Minute, 9*time.Minute + 20*time.Second},
+		{"late drawn", "10:01:00.000", config.StartPolicy{Action: config.StartDrawn}, config.StartPolicy{}, "Started", StartLateDrawn, 0, 10*time.Minute + 10*time.Second},
+		{"early default", "09:59:50.000", config.StartPolicy{}, config.StartPolicy{}, "Started", "", 0, 10*time.Minute + 30*time.Second},
+		{"early disqualify", "09:59:50.000", config.StartPolicy{}, config.StartPolicy{Action: config.StartDisqualify}, "NotStarted", StartEarlyDisqualified, 0, 10*time.Minute + 30*time.Second},
+		{"early penalty", "09:59:50.000", config.StartPolicy{}, penalty, "Started", StartEarlyPenalty, time.Minute, 10*time.Minute + 30*time.Second},
+		{"early drawn", "09:59:50.000", config.StartPolicy{}, config.StartPolicy{Action: config.StartDrawn}, "Started", StartEarlyDrawn, 0, 10*time.Minute + 10*time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			processor, logs := newTestProcessor(t, func(cfg *config.Config) {
+				cfg.LateStart = test.late
+				cfg.EarlyStart = test.early
+			})
+			processor.Process(Event{Time: "09:00:00.000", EventID: EventRegistered, CompetitorID: 1, ExtraParams: []string{}})
+			processor.Process(Event{Time: "09:30:00.000", EventID: EventStartTimeDrawn, CompetitorID: 1, ExtraParams: []string{"10:00:10.000"}})
+			processor.Process(Event{Time: test.start, EventID: EventStarted, CompetitorID: 1, ExtraParams: []string{}})
+			processor.Process(Event{Time: "10:10:20.000", EventID: EventLapEnded, CompetitorID: 1, ExtraParams: []string{}})
+
+			comp := processor.Competitors()[1]
+			if comp.Status != test.status || comp.StartOutcome != test.outcome || comp.StartPenalty != test.startPenalty {
+				t.Errorf("Expected %s %q %v, got %s %q %v", test.status, test.outcome, test.startPenalty,
+					comp.Status, comp.StartOutcome, comp.StartPenalty)
+			}
+			if len(comp.LapTimes) != 1 || comp.LapTimes[0] != test.lapTime {
+				t.Errorf("Expected lap time %v, got %v", test.lapTime, comp.LapTimes)
+			}
+			disqualified, notStarted := false, false
+			for _, event := range processor.OutgoingEvents() {
+				disqualified = disqualified || event.EventID == EventDisqualified
+				notStarted = notStarted || event.EventID == EventDidNotStart
+			}
+			if disqualified != (test.status == "NotStarted") || notStarted {
+				t.Errorf("Unexpected outgoing events: %v", processor.OutgoingEvents())
+			}
+			warned := logs.FilterMessage("Process: late start").Len() + logs.FilterMessage("Process: early start").Len()
+			if (warned != 0) != (test.outcome != "") {
+				t.Errorf("Expected start warning only with an outcome, got %d", warned)
+			}
+		})
+	}
+}
+
+// TestTimingReference tests the moment the first lap is measured from
+func TestTimingReference(t *testing.T) {
+	tests := []struct {
+		name      string
+		reference string
+		draw      bool
+		lapTime   time.Duration
+	}{
+		{"default", "", true, 10 * time.Minute},
+		{"actual", config.TimingActual, true, 10 * time.Minute},
+		{"scheduled", config.TimingScheduled, true, 10*time.Minute + 5*time.Second},
+		{"gate", config.TimingGate, true, 10*time.Minute + 40*time.Second},
+		{"scheduled without draw", config.TimingScheduled, false, 10 * time.Minute},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			processor, logs := newTestProcessor(t, func(cfg *config.Config) {
+				cfg.TimingReference = test.reference
+			})
+			processor.Process(Event{Time: "09:00:00.000", EventID: EventRegistered, CompetitorID: 1, ExtraParams: []string{}})
+			if test.draw {
+				processor.Process(Event{Time: "09:30:00.000", EventID: EventStartTimeDrawn, CompetitorID: 1, ExtraParams: []string{"10:00:00.000"}})
+			}
+			processor.Process(Event{Time: "09:59:25.000", EventID: EventOnStartLine, CompetitorID: 1, ExtraParams: []string{}})
+			processor.Process(Event{Time: "10:00:05.000", EventID: EventStarted, CompetitorID: 1, ExtraParams: []string{}})
+			processor.Process(Event{Time: "10:10:05.000", EventID: EventLapEnded, CompetitorID: 1, ExtraParams: []string{}})
+
+			comp := processor.Competitors()[1]
+			if len(comp.LapTimes) != 1 || comp.LapTimes[0] != test.lapTime {
+				t.Errorf("Expected lap time %v, got %v", test.lapTime, comp.LapTimes)
+			}
+			if n := logs.FilterMessage("Process: timing reference is unknown").Len(); (n != 0) != !test.draw {
+				t.Errorf("Unexpected number of unknown reference warnings: %d", n)
+			}
+		})
+	}
+}
+
+// TestLateArrival tests that the competitor who comes to the start line after the deadline is not raised
+// as did not start and gets the late start policy
+func TestLateArrival(t *testing.T) {
+	tests := []struct {
+		name    string
+		late    config.StartPolicy
+		outcome string
+	}{
+		{"penalty", config.StartPolicy{Action: config.StartPenalty, Penalty: "1m"}, StartLatePenalty},
+		{"drawn", config.StartPolicy{Action: config.StartDrawn}, StartLateDrawn},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			processor, _ := newTestProcessor(t, func(cfg *config.Config) {
+				cfg.LateStart = test.late
+			})
+			for _, event := range []Event{
+				{Time: "09:00:00.000", EventID: EventRegistered, CompetitorID: 1, ExtraParams: []string{}},
+				{Time: "09:30:00.000", EventID: EventStartTimeDrawn, CompetitorID: 1, ExtraParams: []string{"10:01:00.000"}},
+				{Time: "10:02:00.000", EventID: EventOnStartLine, CompetitorID: 1, ExtraParams: []string{}},
+				{Time: "10:02:05.000", EventID: EventStarted, CompetitorID: 1, ExtraParams: []string{}},
+			} {
+				processor.Process(event)
+			}
+			for _, event := range processor.OutgoingEvents() {
+				if event.EventID == EventDidNotStart {
+					t.Errorf("Unexpected did not start: %v", event)
+				}
+			}
+			if comp := processor.Competitors()[1]; comp.Status != "Started" || comp.StartOutcome != test.outcome {
+				t.Errorf("Expected Started %q, got %s %q", test.outcome, comp.Status, comp.StartOutcome)
+			}
+		})
+	}
+}
+
+// TestDidNotStart tests that did not start event is raised by the next event time or by the clock tick
+// once the drawn start time plus StartDelta has passed
+func TestDidNotStart(t *testing.T) {
+	processor, _ := newTestProcessor(t, nil)
+	for _, event := range []Event{
+		{Time: "09:00:00.000", EventID: EventRegistered, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "09:00:01.000", EventID: EventRegistered, CompetitorID: 2, ExtraParams: []string{}},
+		{Time: "09:00:02.000", EventID: EventRegistered, CompetitorID: 3, ExtraParams: []string{}},
+		{Time: "09:30:00.000", EventID: EventStartTimeDrawn, CompetitorID: 1, ExtraParams: []string{"10:00:00.000"}},
+		{Time: "09:30:01.000", EventID: EventStartTimeDrawn, CompetitorID: 2, ExtraParams: []string{"10:01:00.000"}},
+		{Time: "09:30:02.000", EventID: EventStartTimeDrawn, CompetitorID: 3, ExtraParams: []string{"10:02:00.000"}},
+		{Time: "09:59:00.000", EventID: EventOnStartLine, CompetitorID: 1, ExtraParams: []string{}},
+	} {
+		processor.Process(event)
+	}
+
+	outgoing := processor.Process(Event{Time: "10:01:40.000", EventID: EventStarted, CompetitorID: 1, ExtraParams: []string{}})
+	if len(outgoing) == 0 || outgoing[0].EventID != EventDidNotStart || outgoing[0].CompetitorID != 2 || outgoing[0].Time != "10:01:30.000" {
+		t.Fatalf("Expected did not start of competitor 2 by the next event, got %v", outgoing)
+	}
+	if comp := processor.Competitors()[2]; comp.Status != "NotStarted" || comp.StartOutcome != StartDidNotStart {
+		t.Errorf("Unexpected competitor 2: %s %q", comp.Status, comp.StartOutcome)
+	}
+	if outgoing := processor.Tick(mustResolve(t, "10:02:30.000")); len(outgoing) != 0 {
+		t.Errorf("Expected nothing before the deadline, got %v", outgoing)
+	}
+	outgoing = processor.Tick(mustResolve(t, "10:02:31.000"))
+	if len(outgoing) != 1 || outgoing[0].CompetitorID != 3 {
+		t.Errorf("Expected did not start of competitor 3 by the clock tick, got %v", outgoing)
+	}
+	if outgoing := processor.Tick(mustResolve(t, "10:05:00.000")); len(outgoing) != 0 {
+		t.Errorf("Expected did not start to be raised once, got %v", outgoing)
+	}
+}
+
+// TestPredict tests that predictions of competitors on course follow every event: pace of the laps without
+// penalty laps, expected misses on the remaining ranges and ranks among finished and predicted results
+func TestPredict(t *testing.T) {
+	processor, _ := newTestProcessor(t, func(cfg *config.Config) {
+		cfg.FiringLines = 2
+	})
+	process := func(events ...Event) {
+		for _, event := range events {
+			processor.Process(event)
+		}
+	}
+	hits := func(competitor int, at string, targets ...string) []Event {
+		var events []Event
+		for _, target := range targets {
+			events = append(events, Event{Time: at, EventID: EventTargetHit, CompetitorID: competitor, ExtraParams: []string{target}})
+		}
+		return events
+	}
+	process(
+		Event{Time: "09:00:00.000", EventID: EventRegistered, CompetitorID: 1},
+		Event{Time: "09:00:01.000", EventID: EventRegistered, CompetitorID: 2},
+		Event{Time: "09:30:00.000", EventID: EventStartTimeDrawn, CompetitorID: 1, ExtraParams: []string{"10:00:00.000"}},
+		Event{Time: "09:30:01.000", EventID: EventStartTimeDrawn, CompetitorID: 2, ExtraParams: []string{"10:01:00.000"}},
+		Event{Time: "09:59:00.000", EventID: EventOnStartLine, CompetitorID: 1},
+		Event{Time: "10:00:00.000", EventID: EventStarted, CompetitorID: 1},
+		Event{Time: "10:00:30.000", EventID: EventOnStartLine, CompetitorID: 2},
+		Event{Time: "10:01:00.000", EventID: EventStarted, CompetitorID: 2},
+		Event{Time: "10:04:00.000", EventID: EventOnFiringRange, CompetitorID: 1, ExtraParams: []string{"1"}},
+	)
+	process(hits(1, "10:04:10.000", "1", "2", "3")...)
+	process(
+		Event{Time: "10:04:30.000", EventID: EventLeftFiringRange, CompetitorID: 1},
+		Event{Time: "10:04:35.000", EventID: EventEnteredPenalty, CompetitorID: 1},
+		Event{Time: "10:05:00.000", EventID: EventOnFiringRange, CompetitorID: 2, ExtraParams: []string{"1"}},
+		Event{Time: "10:05:15.000", EventID: EventLeftPenalty, CompetitorID: 1},
+	)
+	process(hits(2, "10:05:20.000", "1", "2", "3", "4", "5")...)
+	process(Event{Time: "10:05:30.000", EventID: EventLeftFiringRange, CompetitorID: 2})
+
+	comp1, comp2 := processor.Competitors()[1], processor.Competitors()[2]
+	if comp1.Prediction != (Prediction{}) {
+		t.Errorf("Expected no prediction before the first lap, got %+v", comp1.Prediction)
+	}
+	process(Event{Time: "10:10:00.000", EventID: EventLapEnded, CompetitorID: 1})
+	// lap 10:00 with 40s of penalty laps, 2 misses of 5 give 2 expected penalty laps of 20s on the last range
+	expected1 := Prediction{Finish: mustResolve(t, "10:20:00.000"), Total: 21*time.Minute + 20*time.Second}
+	if comp1.Prediction != expected1 {
+		t.Errorf("Expected prediction %+v, got %+v", expected1, comp1.Prediction)
+	}
+	if ranks := PredictedRanks(processor.Competitors()); ranks[1] != 1 {
+		t.Errorf("Expected competitor 1 to be predicted first, got %v", ranks)
+	}
+
+	process(Event{Time: "10:10:30.000", EventID: EventLapEnded, CompetitorID: 2})
+	expected2 := Prediction{Finish: mustResolve(t, "10:20:00.000"), Total: 19 * time.Minute}
+	if comp2.Prediction != expected2 {
+		t.Errorf("Expected prediction %+v, got %+v", expected2, comp2.Prediction)
+	}
+	if comp1.Prediction != expected1 {
+		t.Errorf("Expected prediction of competitor 1 to stay %+v, got %+v", expected1, comp1.Prediction)
+	}
+	if ranks := PredictedRanks(processor.Competitors()); ranks[1] != 2 || ranks[2] != 1 {
+		t.Errorf("Expected competitor 2 to be predicted first and competitor 1 second, got %v", ranks)
+	}
+
+	process(Event{Time: "10:14:30.000", EventID: EventOnFiringRange, CompetitorID: 2, ExtraParams: []string{"2"}})
+	process(hits(2, "10:14:40.000", "1", "2", "3", "4", "5")...)
+	process(
+		Event{Time: "10:15:00.000", EventID: EventLeftFiringRange, CompetitorID: 2},
+		Event{Time: "10:20:00.000", EventID: EventLapEnded, CompetitorID: 2},
+	)
+	if comp2.Status != "Finished" || comp2.Prediction != (Prediction{}) {
+		t.Errorf("Expected finished competitor without prediction, got %s %+v", comp2.Status, comp2.Prediction)
+	}
+	if ranks := PredictedRanks(processor.Competitors()); len(ranks) != 1 || ranks[1] != 2 {
+		t.Errorf("Expected only competitor 1 to be ranked, second after the finished one, got %v", ranks)
+	}
+}
+
+// newTestProcessor creates processor with a two-lap test config changed by mutate and logs observed at info level
+func newTestProcessor(t *testing.T, mutate func(cfg *config.Config)) (*Processor, *observer.ObservedLogs) {
+	t.Helper()
+	cfg := &config.Config{
+		Laps:        2,
+		LapLen:      1000,
+		PenaltyLen:  100,
+		FiringLines: 1,
+		Start:       "10:00:00.000",
+		StartDelta:  "00:00:30",
+	}
+	if mutate != nil {
+		mutate(cfg)
+	}
+	core, logs := observer.New(zap.InfoLevel)
+	return NewProcessor(cfg, zap.New(core)), logs
+}
+
+// mustResolve resolves time of day on the reference clock of a race without date
+func mustResolve(t *testing.T, s string) time.Time {
+	resolved, err := timestamp.Resolve(s, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
@@ -0,0 +1,165 @@
+package process
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/timestamp"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Outcomes of a start out of time shown in the report
+const (
+	StartLateDisqualified  = "late_start_disqualified"
+	StartLatePenalty       = "late_start_penalty"
+	StartLateDrawn         = "late_start_drawn"
+	StartEarlyDisqualified = "early_start_disqualified"
+	StartEarlyPenalty      = "early_start_penalty"
+	StartEarlyDrawn        = "early_start_drawn"
+	// StartDidNotStart is the outcome of the competitor who never came to the start line
+	StartDidNotStart = "did_not_start"
+)
+
+// startOutcomes are outcomes by whether the start is late and by the policy action
+var startOutcomes = map[bool]map[string]string{
+	true: {
+		config.StartDisqualify: StartLateDisqualified,
+		config.StartPenalty:    StartLatePenalty,
+		config.StartDrawn:      StartLateDrawn,
+	},
+	false: {
+		config.StartDisqualify: StartEarlyDisqualified,
+		config.StartPenalty:    StartEarlyPenalty,
+		config.StartDrawn:      StartEarlyDrawn,
+	},
+}
+
+// IsStartOutcome reports whether the key is an outcome of a start out of time rather than a finish rejection reason
+func IsStartOutcome(key string) bool {
+	if key == StartDidNotStart {
+		return true
+	}
+	for _, outcomes := range startOutcomes {
+		for _, outcome := range outcomes {
+			if outcome == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// timingStart returns the time the first lap of the competitor is measured from by the timing reference,
+// the actual start is used if the reference time is unknown
+func (p *Processor) timingStart(comp *Competitor, event Event) time.Time {
+	switch p.config.TimingReference {
+	case config.TimingScheduled:
+		if !comp.StartTime.IsZero() {
+			return comp.StartTime
+		}
+		p.warn(event, "Process: timing reference is unknown", fmt.Errorf("start time is not drawn"))
+	case config.TimingGate:
+		if !comp.GateTime.IsZero() {
+			return comp.GateTime
+		}
+		p.warn(event, "Process: timing reference is unknown", fmt.Errorf("competitor has not passed the start gate"))
+	}
+	return comp.ActualStart
+}
+
+// checkStart applies the late or early start policy to the competitor who started out of time
+func (p *Processor) checkStart(comp *Competitor, event Event, eventTime time.Time, outgoing []Event) []Event {
+	startDelta, err := p.config.StartDeltaDuration()
+	if err != nil {
+		p.warn(event, "Process: error in start delta format", err)
+	}
+	offset := eventTime.Sub(comp.StartTime)
+	late := offset > startDelta
+	policy := p.config.EarlyStart
+	message := "Process: early start"
+	switch {
+	case late:
+		policy = p.config.LateStart
+		message = "Process: late start"
+		if policy.Action == "" {
+			policy.Action = config.StartDisqualify
+		}
+	case offset >= 0 || policy.Action == "":
+		return outgoing
+	}
+
+	comp.StartOutcome = startOutcomes[late][policy.Action]
+	fields := append(EventFields(event), zap.Duration("offset", offset), zap.String("policy", policy.Action))
+	switch policy.Action {
+	case config.StartDisqualify:
+		p.logger.Warn(message, fields...)
+		comp.Status = "NotStarted"
+		return p.emit(outgoing, Event{
+			Time:         event.Time,
+			EventID:      EventDisqualified,
+			CompetitorID: comp.ID,
+		})
+	case config.StartPenalty:
+		penalty, err := policy.PenaltyDuration()
+		if err != nil {
+			p.warn(event, "Process: error in start penalty format", err)
+		}
+		comp.StartPenalty = penalty
+		p.logger.Warn(message, append(fields, zap.Duration("penalty", penalty))...)
+	case config.StartDrawn:
+		comp.TimingStart = comp.StartTime
+		comp.LastLapTime = comp.StartTime
+		p.logger.Warn(message, fields...)
+	}
+	return outgoing
+}
+
+// checkNotStarted raises did not start event for every competitor whose drawn start time plus StartDelta
+// has passed by now, while the competitor has come neither to the start line nor to the start
+func (p *Processor) checkNotStarted(now time.Time, outgoing []Event) []Event {
+	if len(p.awaitingStart) == 0 {
+		return outgoing
+	}
+	startDelta, err := p.config.StartDeltaDuration()
+	if err != nil {
+		return outgoing
+	}
+	_, dated, _ := timestamp.Parse(p.config.Start)
+	ids := make([]int, 0, len(p.awaitingStart))
+	for id := range p.awaitingStart {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		comp := p.competitors[id]
+		deadline := comp.StartTime.Add(startDelta)
+		if !deadline.Before(now) {
+			continue
+		}
+		delete(p.awaitingStart, id)
+		comp.Status = "NotStarted"
+		comp.StartOutcome = StartDidNotStart
+		outgoing = p.emit(outgoing, Event{
+			Time:         timestamp.Format(deadline, dated),
+			EventID:      EventDidNotStart,
+			CompetitorID: id,
+		})
+	}
+	return outgoing
+}
+
+// Tick advances the race clock to now without an event and returns outgoing events due by then, such as
+// did not start. In streaming mode it is called as time passes: buffered events older than the reorder
+// window are applied, later ones are awaited
+func (p *Processor) Tick(now time.Time) []Event {
+	if p.clock.IsZero() || now.After(p.clock) {
+		p.clock = now
+	}
+	var outgoing []Event
+	for len(p.pending) > 0 && !p.pending[0].at.After(now.Add(-p.window)) {
+		outgoing = append(outgoing, p.release()...)
+	}
+	return p.checkNotStarted(now.Add(-p.window), outgoing)
+}
@@ -0,0 +1,223 @@
+package replay
+
+import (
+	"TelecomTask/internal/process"
+	"TelecomTask/internal/timestamp"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Handler receives events dispatched by Replayer
+type Handler interface {
+	// Reset drops the state accumulated so far, it is called before seeking backwards
+	Reset()
+	// Handle processes single event
+	Handle(event process.Event)
+}
+
+// Ticker is implemented by handlers which act on the passage of simulated time between events
+type Ticker interface {
+	// Tick is called with current simulated time at least every TickInterval of simulated time
+	Tick(now time.Time)
+}
+
+// TickInterval is how often in simulated time Ticker handlers are called while waiting for the next event
+const TickInterval = time.Second
+
+// Replayer dispatches recorded events when the simulated clock reaches their time
+type Replayer struct {
+	events  []process.Event
+	times   []time.Time
+	handler Handler
+
+	mu     sync.Mutex
+	pos    int
+	clock  time.Time
+	wallAt time.Time
+	speed  float64
+	paused bool
+	seekTo *time.Time
+	wake   chan struct{}
+}
+
+// New creates replayer for events with given speed, 1 means real time.
+// Events are dispatched in the order of the slice: an event older than the ones before it is dispatched right
+// after them, as it was received, and the handler reorders it like in the live race
+func New(events []process.Event, handler Handler, speed float64) (*Replayer, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("New: speed must be positive: %v", speed)
+	}
+	times := make([]time.Time, len(events))
+	var prev time.Time
+	for i, event := range events {
+		t, err := timestamp.Resolve(event.Time, prev)
+		if err != nil {
+			return nil, fmt.Errorf("New: error parsing time of event %d: %w", i+1, err)
+		}
+		if i > 0 && t.Before(prev) {
+			t = prev
+		}
+		times[i] = t
+		prev = t
+	}
+	r := &Replayer{
+		events:  events,
+		times:   times,
+		handler: handler,
+		speed:   speed,
+		wake:    make(chan struct{}, 1),
+	}
+	if len(times) > 0 {
+		r.clock = times[0]
+	}
+	return r, nil
+}
+
+// Now returns current simulated time
+func (r *Replayer) Now() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.nowLocked()
+}
+
+// ParseTime parses time of the race, time of day is placed after the first event
+func (r *Replayer) ParseTime(s string) (time.Time, error) {
+	var first time.Time
+	if len(r.times) > 0 {
+		first = r.times[0]
+	}
+	t, err := timestamp.Resolve(s, first)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ParseTime: %w", err)
+	}
+	return t, nil
+}
+
+// nowLocked returns simulated time, r.mu must be held. The clock does not run before Run is called
+func (r *Replayer) nowLocked() time.Time {
+	if r.paused || r.wallAt.IsZero() {
+		return r.clock
+	}
+	elapsed := time.Duration(float64(time.Since(r.wallAt)) * r.speed)
+	return r.clock.Add(elapsed)
+}
+
+// syncLocked fixes current simulated time as a new reference point, r.mu must be held
+func (r *Replayer) syncLocked() {
+	if r.wallAt.IsZero() {
+		return
+	}
+	r.clock = r.nowLocked()
+	r.wallAt = time.Now()
+}
+
+// notify wakes up Run loop after changing the replay parameters
+func (r *Replayer) notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Pause stops the simulated clock
+func (r *Replayer) Pause() {
+	r.mu.Lock()
+	r.syncLocked()
+	r.paused = true
+	r.mu.Unlock()
+	r.notify()
+}
+
+// Resume starts the simulated clock after Pause
+func (r *Replayer) Resume() {
+	r.mu.Lock()
+	r.syncLocked()
+	r.paused = false
+	r.mu.Unlock()
+	r.notify()
+}
+
+// SetSpeed changes the speed of the simulated clock
+func (r *Replayer) SetSpeed(speed float64) error {
+	if speed <= 0 {
+		return fmt.Errorf("SetSpeed: speed must be positive: %v", speed)
+	}
+	r.mu.Lock()
+	r.syncLocked()
+	r.speed = speed
+	r.mu.Unlock()
+	r.notify()
+	return nil
+}
+
+// Seek moves the simulated clock to t, all events up to t are dispatched immediately.
+// Seeking backwards resets the handler and replays events from the beginning
+func (r *Replayer) Seek(t time.Time) {
+	r.mu.Lock()
+	r.seekTo = &t
+	r.mu.Unlock()
+	r.notify()
+}
+
+// Run dispatches events until all of them are replayed or ctx is cancelled
+func (r *Replayer) Run(ctx context.Context) error {
+	r.mu.Lock()
+	r.wallAt = time.Now()
+	r.mu.Unlock()
+	for {
+		r.mu.Lock()
+		if r.seekTo != nil {
+			backwards := r.seekTo.Before(r.nowLocked())
+			r.clock = *r.seekTo
+			r.wallAt = time.Now()
+			r.seekTo = nil
+			if backwards {
+				r.pos = 0
+				r.mu.Unlock()
+				r.handler.Reset()
+				continue
+			}
+		}
+		if r.pos >= len(r.events) {
+			r.mu.Unlock()
+			return nil
+		}
+		next := r.times[r.pos]
+		now := r.nowLocked()
+		if !next.After(now) {
+			event := r.events[r.pos]
+			r.pos++
+			r.mu.Unlock()
+			r.handler.Handle(event)
+			continue
+		}
+		wait := next.Sub(now)
+		ticker, ticks := r.handler.(Ticker)
+		if ticks && wait > TickInterval {
+			wait = TickInterval
+		}
+		paused := r.paused
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if !paused {
+			timer = time.NewTimer(time.Duration(float64(wait) / r.speed))
+			timeout = timer.C
+		}
+		r.mu.Unlock()
+		if ticks && !paused {
+			ticker.Tick(now)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+		case <-r.wake:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package replay
+
+import (
+	"TelecomTask/internal/process"
+	"context"
+	"testing"
+	"time"
+)
+
+// recorder remembers dispatched events
+type recorder struct {
+	handled []string
+	resets  int
+}
+
+func (r *recorder) Reset() {
+	r.resets++
+	r.handled = nil
+}
+
+func (r *recorder) Handle(event process.Event) {
+	r.handled = append(r.handled, event.Time)
+}
+
+var testEvents = []process.Event{
+	{Time: "10:00:00.000", EventID: 1, CompetitorID: 1, ExtraParams: []string{}},
+	{Time: "10:00:00.500", EventID: 1, CompetitorID: 2, ExtraParams: []string{}},
+	{Time: "10:00:01.000", EventID: 2, CompetitorID: 1, ExtraParams: []string{"10:30:00.000"}},
+	{Time: "10:00:02.000", EventID: 2, CompetitorID: 2, ExtraParams: []string{"10:31:00.000"}},
+}
+
+// TestNew tests validation of replay parameters
+func TestNew(t *testing.T) {
+	if _, err := New(testEvents, &recorder{}, 0); err == nil {
+		t.Error("Expected error for zero speed")
+	}
+	invalid := []process.Event{{Time: "bad", EventID: 1, CompetitorID: 1, ExtraParams: []string{}}}
+	if _, err := New(invalid, &recorder{}, 1); err == nil {
+		t.Error("Expected error for invalid event time")
+	}
+}
+
+// TestRun tests that all events are dispatched in order with simulated clock
+func TestRun(t *testing.T) {
+	rec := &recorder{}
+	replayer, err := New(testEvents, rec, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := replayer.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("Expected replay to follow the simulated clock, finished in %v", elapsed)
+	}
+	if len(rec.handled) != len(testEvents) {
+		t.Fatalf("Expected %d events, got %d", len(testEvents), len(rec.handled))
+	}
+	for i, event := range testEvents {
+		if rec.handled[i] != event.Time {
+			t.Errorf("Event %d: expected %s, got %s", i, event.Time, rec.handled[i])
+		}
+	}
+}
+
+// TestRunOutOfOrder tests that an event older than the previous ones is dispatched after them as it was received
+func TestRunOutOfOrder(t *testing.T) {
+	rec := &recorder{}
+	unordered := []process.Event{testEvents[0], testEvents[2], testEvents[1], testEvents[3]}
+	replayer, err := New(unordered, rec, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := replayer.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rec.handled) != len(unordered) {
+		t.Fatalf("Expected %d events, got %d", len(unordered), len(rec.handled))
+	}
+	for i, event := range unordered {
+		if rec.handled[i] != event.Time {
+			t.Errorf("Event %d: expected %s, got %s", i, event.Time, rec.handled[i])
+		}
+	}
+}
+
+// TestSeek tests seeking forwards and backwards
+func TestSeek(t *testing.T) {
+	rec := &recorder{}
+	replayer, err := New(testEvents, rec, 0.001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	end, _ := time.Parse("15:04:05.000", "10:00:02.000")
+	replayer.Seek(end)
+	if err := replayer.Run(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rec.handled) != len(testEvents) {
+		t.Errorf("Expected %d events after seek, got %d", len(testEvents), len(rec.handled))
+	}
+
+	rec = &recorder{}
+	replayer, err = New(testEvents, rec, 0.001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	middle, _ := time.Parse("15:04:05.000", "10:00:01.000")
+	start, _ := time.Parse("15:04:05.000", "10:00:00.000")
+	done := make(chan error)
+	go func() {
+		done <- replayer.Run(ctx)
+	}()
+	replayer.Seek(middle)
+	time.Sleep(20 * time.Millisecond)
+	replayer.Pause()
+	replayer.Seek(start)
+	time.Sleep(20 * time.Millisecond)
+	replayer.Seek(end)
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec.resets != 1 {
+		t.Errorf("Expected 1 reset, got %d", rec.resets)
+	}
+	if len(rec.handled) != len(testEvents) {
+		t.Errorf("Expected %d events after reset, got %d", len(testEvents), len(rec.handled))
+	}
+}
+
+// TestPause tests that paused replay does not dispatch events
+func TestPause(t *testing.T) {
+	rec := &recorder{}
+	replayer, err := New(testEvents, rec, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer.Pause()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := replayer.Run(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+	if len(rec.handled) != 1 {
+		t.Errorf("Expected only the first event while paused, got %d", len(rec.handled))
+	}
+}
+
+// TestPauseWhileRunning tests pausing and resuming from another goroutine while the replay runs and ticks,
+// it is meant to be run with -race
+func TestPauseWhileRunning(t *testing.T) {
+	rec := &tickRecorder{}
+	replayer, err := New(testEvents, rec, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- replayer.Run(context.Background())
+	}()
+	for i := 0; i < 10; i++ {
+		replayer.Pause()
+		time.Sleep(time.Millisecond)
+		replayer.Resume()
+		time.Sleep(time.Millisecond)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rec.handled) != len(testEvents) {
+		t.Errorf("Expected %d events, got %d", len(testEvents), len(rec.handled))
+	}
+}
+
+// tickRecorder remembers dispatched events and ticks of the simulated clock
+type tickRecorder struct {
+	recorder
+	ticks []time.Time
+}
+
+func (r *tickRecorder) Tick(now time.Time) {
+	r.ticks = append(r.ticks, now)
+}
+
+// TestTick tests that ticker handler is called every TickInterval of simulated time between events
+func TestTick(t *testing.T) {
+	events := []process.Event{
+		{Time: "10:00:00.000", EventID: 1, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "10:00:05.000", EventID: 1, CompetitorID: 2, ExtraParams: []string{}},
+	}
+	rec := &tickRecorder{}
+	replayer, err := New(events, rec, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := replayer.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rec.ticks) < 5 {
+		t.Fatalf("Expected a tick every simulated second, got %v", rec.ticks)
+	}
+	for i := 1; i < len(rec.ticks); i++ {
+		if rec.ticks[i].Before(rec.ticks[i-1]) || rec.ticks[i].Sub(rec.ticks[i-1]) > 2*TickInterval {
+			t.Errorf("Unexpected ticks: %v", rec.ticks)
+			break
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package scoreboard
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/process"
+	"TelecomTask/internal/timestamp"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ANSI escape sequences understood by any Linux terminal
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	bold        = "\x1b[1m"
+	green       = "\x1b[32m"
+	red         = "\x1b[31m"
+	reset       = "\x1b[0m"
+)
+
+// Row is a line of the live standings
+type Row struct {
+	Rank         int
+	CompetitorID int
+	Status       string
+	// LapsDone is the number of completed laps
+	LapsDone int
+	// Range is the firing range the competitor is on, 0 if not on a range
+	Range     int
+	InPenalty bool
+	// PenaltyLaps is the number of penalty laps given so far, PenaltyServed of them are run
+	PenaltyLaps   int
+	PenaltyServed int
+	// Elapsed is the time of the completed laps, the total time of the report for finished competitors
+	Elapsed time.Duration
+	// Gap is the time behind the leader at the last lap both completed, 0 for the leader or without laps
+	Gap time.Duration
+	// Prediction is the expected result of the competitor on course, PredictedRank is the expected place
+	Prediction    process.Prediction
+	PredictedRank int
+}
+
+// racing reports whether the row is still ranked: the competitor has finished or is on course
+func (r Row) racing() bool {
+	return r.Status == "Finished" || r.Status == "Started"
+}
+
+// Rows ranks competitors: finished ones by total time, then those on course by completed laps and time,
+// then the rest by id. Gap to the leader is measured at the last lap completed by both
+func Rows(cfg *config.Config, competitors map[int]*process.Competitor) []Row {
+	ranks := process.PredictedRanks(competitors)
+	rows := make([]Row, 0, len(competitors))
+	for _, comp := range competitors {
+		row := Row{
+			CompetitorID:  comp.ID,
+			Status:        comp.Status,
+			LapsDone:      len(comp.LapTimes),
+			InPenalty:     comp.InPenalty,
+			PenaltyLaps:   comp.PenaltyLaps + comp.PenaltyLapsServed,
+			PenaltyServed: comp.PenaltyLapsServed,
+			Elapsed:       elapsed(comp, len(comp.LapTimes)),
+			Prediction:    comp.Prediction,
+			PredictedRank: ranks[comp.ID],
+		}
+		if comp.OnFiringRange {
+			row.Range = comp.FiringRange
+		}
+		if comp.Status == "Finished" {
+			row.Elapsed = process.TotalTime(comp)
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.racing() != b.racing() {
+			return a.racing()
+		}
+		if !a.racing() {
+			return a.CompetitorID < b.CompetitorID
+		}
+		if (a.Status == "Finished") != (b.Status == "Finished") {
+			return a.Status == "Finished"
+		}
+		if a.LapsDone != b.LapsDone {
+			return a.LapsDone > b.LapsDone
+		}
+		if a.Elapsed != b.Elapsed {
+			return a.Elapsed < b.Elapsed
+		}
+		return a.CompetitorID < b.CompetitorID
+	})
+	for i := range rows {
+		rows[i].Rank = i + 1
+		if i == 0 || !rows[i].racing() || rows[i].LapsDone == 0 {
+			continue
+		}
+		if rows[i].Status == "Finished" {
+			rows[i].Gap = rows[i].Elapsed - rows[0].Elapsed
+			continue
+		}
+		laps := min(rows[i].LapsDone, rows[0].LapsDone)
+		rows[i].Gap = elapsed(competitors[rows[i].CompetitorID], laps) - elapsed(competitors[rows[0].CompetitorID], laps)
+	}
+	return rows
+}
+
+// elapsed returns time of the first laps of the competitor
+func elapsed(comp *process.Competitor, laps int) time.Duration {
+	var total time.Duration
+	for _, lapTime := range comp.LapTimes[:laps] {
+		total += lapTime
+	}
+	return total
+}
+
+// Board is the live scoreboard of the race: standings and a feed of recent events
+type Board struct {
+	cfg      *config.Config
+	catalog  *i18n.Catalog
+	feedSize int
+	feed     []process.Event
+}
+
+// New creates scoreboard showing up to feedSize recent events
+func New(cfg *config.Config, catalog *i18n.Catalog, feedSize int) *Board {
+	return &Board{cfg: cfg, catalog: catalog, feedSize: feedSize}
+}
+
+// Record adds the event to the feed, the oldest events leave the feed when it is full
+func (b *Board) Record(event process.Event) {
+	b.feed = append(b.feed, event)
+	if len(b.feed) > b.feedSize {
+		b.feed = b.feed[len(b.feed)-b.feedSize:]
+	}
+}
+
+// Render draws the whole screen: race clock, standings and the feed. Finishes are highlighted green,
+// disqualifications and competitors out of the race red
+func (b *Board) Render(w io.Writer, now time.Time, competitors map[int]*process.Competitor) error {
+	_, dated, _ := timestamp.Parse(b.cfg.Start)
+	var sb strings.Builder
+	sb.WriteString(clearScreen)
+	fmt.Fprintf(&sb, "%s%s %s%s\n\n", bold, b.catalog.BoardClock, timestamp.Format(now, dated), reset)
+	fmt.Fprintf(&sb, "%s%-5s %-11s %-6s %-16s %-9s %-14s %s%s\n", bold,
+		b.catalog.BoardColumns[0], b.catalog.BoardColumns[1], b.catalog.BoardColumns[2],
+		b.catalog.BoardColumns[3], b.catalog.BoardColumns[4], b.catalog.BoardColumns[5],
+		b.catalog.BoardColumns[6], reset)
+	for _, row := range Rows(b.cfg, competitors) {
+		line := fmt.Sprintf("%-5d %-11d %-6s %-16s %-9s %-14s %s", row.Rank, row.CompetitorID,
+			fmt.Sprintf("%d/%d", row.LapsDone, b.cfg.Laps), b.position(row),
+			fmt.Sprintf("%d/%d", row.PenaltyServed, row.PenaltyLaps), b.gap(row), b.prediction(row, dated))
+		line = strings.TrimRight(line, " ")
+		sb.WriteString(highlight(row.Status, line) + "\n")
+	}
+	fmt.Fprintf(&sb, "\n%s%s%s\n", bold, b.catalog.BoardFeed, reset)
+	for _, event := range b.feed {
+		line := fmt.Sprintf("%s %s", event.Time, event.LocalizedMessage(b.catalog))
+		switch event.EventID {
+		case process.EventFinished:
+			line = green + line + reset
+		case process.EventDisqualified, process.EventDidNotStart:
+			line = red + line + reset
+		}
+		sb.WriteString(line + "\n")
+	}
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("Render: %w", err)
+	}
+	return nil
+}
+
+// position describes where the competitor is: on a firing range, on penalty laps, on course or the final status
+func (b *Board) position(row Row) string {
+	switch {
+	case row.Status != "Started":
+		return b.catalog.Status(row.Status)
+	case row.Range != 0:
+		return fmt.Sprintf(b.catalog.BoardRange, row.Range)
+	case row.InPenalty:
+		return b.catalog.BoardPenalty
+	}
+	return "-"
+}
+
+// gap formats time behind the leader
+func (b *Board) gap(row Row) string {
+	switch {
+	case row.Gap > 0:
+		return "+" + b.catalog.Duration(process.FormatDuration(row.Gap))
+	case row.Gap < 0:
+		return "-" + b.catalog.Duration(process.FormatDuration(-row.Gap))
+	}
+	return ""
+}
+
+// prediction formats the expected finish time and place of the competitor on course
+func (b *Board) prediction(row Row, dated bool) string {
+	if row.Status != "Started" || row.Prediction.Total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s (%d)", timestamp.Format(row.Prediction.Finish, dated), row.PredictedRank)
+}
+
+// highlight colors the standings line by the competitor status
+func highlight(status, line string) string {
+	switch status {
+	case "Finished":
+		return green + line + reset
+	case "Started", "Registered":
+		return line
+	}
+	return red + line + reset
+}
@@ -0,0 +1,115 @@
+package scoreboard
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/process"
+	"TelecomTask/internal/timestamp"
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var testConfig = &config.Config{Laps: 2, LapLen: 1000, PenaltyLen: 100, FiringLines: 2, Start: "10:00:00.000", StartDelta: "00:00:30"}
+
+// testCompetitors are two finished competitors, two on course and one disqualified
+func testCompetitors() map[int]*process.Competitor {
+	return map[int]*process.Competitor{
+		1: {ID: 1, Status: "Started", LapTimes: []time.Duration{5 * time.Minute}, OnFiringRange: true, FiringRange: 2,
+			Prediction: process.Prediction{Finish: time.Date(0, 1, 1, 10, 12, 0, 0, time.UTC), Total: 10*time.Minute + 45*time.Second}},
+		2: {ID: 2, Status: "Finished", LapTimes: []time.Duration{5 * time.Minute, 5 * time.Minute},
+			PenaltyTimes: []time.Duration{time.Minute}, PenaltyLapsServed: 2},
+		3: {ID: 3, Status: "NotStarted"},
+		4: {ID: 4, Status: "Started", LapTimes: []time.Duration{4 * time.Minute}, InPenalty: true, PenaltyLaps: 1},
+		5: {ID: 5, Status: "Finished", LapTimes: []time.Duration{5 * time.Minute, 5*time.Minute + 30*time.Second}},
+	}
+}
+
+// TestRows tests ranking and gaps of the live standings
+func TestRows(t *testing.T) {
+	rows := Rows(testConfig, testCompetitors())
+	expected := []struct {
+		id  int
+		gap time.Duration
+	}{{5, 0}, {2, 30 * time.Second}, {4, -time.Minute}, {1, 0}, {3, 0}}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d", len(expected), len(rows))
+	}
+	for i, e := range expected {
+		if rows[i].Rank != i+1 || rows[i].CompetitorID != e.id || rows[i].Gap != e.gap {
+			t.Errorf("Row %d: expected competitor %d with gap %v, got %+v", i+1, e.id, e.gap, rows[i])
+		}
+	}
+	if rows[1].PenaltyLaps != 2 || rows[1].PenaltyServed != 2 || rows[2].PenaltyLaps != 1 || rows[3].Range != 2 {
+		t.Errorf("Unexpected penalties or ranges: %+v", rows)
+	}
+}
+
+// TestRender tests that the screen shows standings and only the recent events with highlights
+func TestRender(t *testing.T) {
+	catalog, _ := i18n.Get("en")
+	board := New(testConfig, catalog, 2)
+	for _, event := range []process.Event{
+		{Time: "10:05:00.000", EventID: process.EventLapEnded, CompetitorID: 1},
+		{Time: "10:10:00.000", EventID: process.EventFinished, CompetitorID: 5},
+		{Time: "10:10:01.000", EventID: process.EventDisqualified, CompetitorID: 3},
+	} {
+		board.Record(event)
+	}
+	var buf bytes.Buffer
+	now := time.Date(0, 1, 1, 10, 11, 0, 0, time.UTC)
+	if err := board.Render(&buf, now, testCompetitors()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	screen := buf.String()
+	for _, expected := range []string{
+		clearScreen,
+		"Race clock 10:11:00.000",
+		"firing range 2",
+		"penalty laps",
+		"+00:00:30.000",
+		"10:12:00.000 (2)",
+		green + "10:10:00.000 The competitor(5) has finished" + reset,
+		red + "10:10:01.000 The competitor(3) is disqualified" + reset,
+	} {
+		if !strings.Contains(screen, expected) {
+			t.Errorf("Expected %q on the screen:\n%s", expected, screen)
+		}
+	}
+	if strings.Contains(screen, "ended the main lap") {
+		t.Errorf("Expected the oldest event to leave the feed:\n%s", screen)
+	}
+}
+
+// TestRecordAccepted tests that the board fed by the processor shows only accepted and outgoing events
+func TestRecordAccepted(t *testing.T) {
+	catalog, _ := i18n.Get("en")
+	board := New(testConfig, catalog, 10)
+	processor := process.NewProcessor(testConfig, zap.NewNop())
+	processor.Observe(board.Record)
+	for _, event := range []process.Event{
+		{Time: "09:00:00.000", EventID: process.EventRegistered, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "09:00:00.000", EventID: process.EventRegistered, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: "09:01:00.000", EventID: process.EventTargetHit, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: "09:30:00.000", EventID: process.EventStartTimeDrawn, CompetitorID: 1, ExtraParams: []string{"10:00:00.000"}},
+	} {
+		processor.Process(event)
+	}
+	now, err := timestamp.Resolve("10:01:00.000", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor.Tick(now)
+	var ids []int
+	for _, event := range board.feed {
+		ids = append(ids, event.EventID)
+	}
+	expected := []int{process.EventRegistered, process.EventStartTimeDrawn, process.EventDidNotStart}
+	if !slices.Equal(ids, expected) {
+		t.Errorf("Expected events %v in the feed, got %v", expected, ids)
+	}
+}
@@ -0,0 +1,245 @@
+package season
+
+import (
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/process"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// PointsTable defines how race places are converted into season points
+type PointsTable struct {
+	// Points are awarded by place in the race: the first value for the 1st place and so on,
+	// places beyond the table get no points
+	Points []int `json:"points"`
+	// DropWorst is the number of worst results of every competitor not counted in the total
+	DropWorst int `json:"dropWorst"`
+	// Categories are competitor ids by category name, used to compute standings of a single category
+	Categories map[string][]int `json:"categories"`
+}
+
+// Race is the resulting table of a single race
+type Race struct {
+	Name    string
+	Reports []process.Report
+}
+
+// RaceResult is the result of a competitor in a single race of the season
+type RaceResult struct {
+	// Place in the race, 0 if the competitor did not finish or did not take part
+	Place   int
+	Points  int
+	Dropped bool
+}
+
+// Standing is the season result of a competitor
+type Standing struct {
+	Rank         int
+	CompetitorID int
+	Points       int
+	Results      []RaceResult
+}
+
+// LoadPointsTable loads points table from json file
+func LoadPointsTable(filename string) (*PointsTable, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("LoadPointsTable: error reading file: %w", err)
+	}
+	var table PointsTable
+	if err = json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("LoadPointsTable: error parsing json: %w", err)
+	}
+	if len(table.Points) == 0 {
+		return nil, fmt.Errorf("LoadPointsTable: points table is empty")
+	}
+	if table.DropWorst < 0 {
+		return nil, fmt.Errorf("LoadPointsTable: dropWorst must not be negative")
+	}
+	return &table, nil
+}
+
+// placePoints returns points for the place in the race
+func (t *PointsTable) placePoints(place int) int {
+	if place < 1 || place > len(t.Points) {
+		return 0
+	}
+	return t.Points[place-1]
+}
+
+// places ranks finished competitors of the race by total time. Competitors with equal time
+// share the place and the next place is skipped
+func places(reports []process.Report) map[int]int {
+	result := make(map[int]int)
+	place := 0
+	prev := ""
+	for i, report := range reports {
+		if _, err := process.ParseDuration(report.TotalTime); err != nil {
+			continue
+		}
+		if report.TotalTime != prev {
+			place = i + 1
+			prev = report.TotalTime
+		}
+		result[report.CompetitorID] = place
+	}
+	return result
+}
+
+// filter leaves only reports of competitors from the set, keeping their order
+func filter(reports []process.Report, competitors map[int]bool) []process.Report {
+	var filtered []process.Report
+	for _, report := range reports {
+		if competitors[report.CompetitorID] {
+			filtered = append(filtered, report)
+		}
+	}
+	return filtered
+}
+
+// dropWorst marks the worst results as not counted. At least one result is always counted,
+// among equal results the later race is dropped first
+func dropWorst(results []RaceResult, n int) {
+	if n > len(results)-1 {
+		n = len(results) - 1
+	}
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if results[order[i]].Points != results[order[j]].Points {
+			return results[order[i]].Points < results[order[j]].Points
+		}
+		return order[i] > order[j]
+	})
+	for _, i := range order[:max(n, 0)] {
+		results[i].Dropped = true
+	}
+}
+
+// countBack compares competitors with equal points by the number of better places:
+// more wins first, then more second places and so on
+func countBack(a, b []RaceResult) int {
+	count := func(results []RaceResult) map[int]int {
+		counts := make(map[int]int)
+		for _, result := range results {
+			if result.Place > 0 {
+				counts[result.Place]++
+			}
+		}
+		return counts
+	}
+	ca, cb := count(a), count(b)
+	maxPlace := 0
+	for place := range ca {
+		maxPlace = max(maxPlace, place)
+	}
+	for place := range cb {
+		maxPlace = max(maxPlace, place)
+	}
+	for place := 1; place <= maxPlace; place++ {
+		if ca[place] != cb[place] {
+			return cb[place] - ca[place]
+		}
+	}
+	return 0
+}
+
+// Compute computes cumulative standings of the season. With non-empty category only its competitors
+// are ranked and their places in every race are counted among the category
+func Compute(table *PointsTable, races []Race, category string) ([]Standing, error) {
+	var members map[int]bool
+	if category != "" {
+		ids, ok := table.Categories[category]
+		if !ok {
+			return nil, fmt.Errorf("Compute: unknown category %s", category)
+		}
+		members = make(map[int]bool, len(ids))
+		for _, id := range ids {
+			members[id] = true
+		}
+	}
+
+	byCompetitor := make(map[int]*Standing)
+	for i, race := range races {
+		reports := race.Reports
+		if members != nil {
+			reports = filter(reports, members)
+		}
+		racePlaces := places(reports)
+		for _, report := range reports {
+			standing, ok := byCompetitor[report.CompetitorID]
+			if !ok {
+				standing = &Standing{CompetitorID: report.CompetitorID, Results: make([]RaceResult, len(races))}
+				byCompetitor[report.CompetitorID] = standing
+			}
+			place := racePlaces[report.CompetitorID]
+			standing.Results[i] = RaceResult{Place: place, Points: table.placePoints(place)}
+		}
+	}
+
+	standings := make([]Standing, 0, len(byCompetitor))
+	for _, standing := range byCompetitor {
+		dropWorst(standing.Results, table.DropWorst)
+		for _, result := range standing.Results {
+			if !result.Dropped {
+				standing.Points += result.Points
+			}
+		}
+		standings = append(standings, *standing)
+	}
+	compare := func(a, b Standing) int {
+		if a.Points != b.Points {
+			return b.Points - a.Points
+		}
+		return countBack(a.Results, b.Results)
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if c := compare(standings[i], standings[j]); c != 0 {
+			return c < 0
+		}
+		return standings[i].CompetitorID < standings[j].CompetitorID
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && compare(standings[i-1], standings[i]) == 0 {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+	return standings, nil
+}
+
+// formatResults formats place and points of every race, not counted points are shown in parentheses
+func formatResults(results []RaceResult) string {
+	formatted := make([]string, len(results))
+	for i, result := range results {
+		place := "-"
+		if result.Place > 0 {
+			place = fmt.Sprint(result.Place)
+		}
+		points := fmt.Sprint(result.Points)
+		if result.Dropped {
+			points = "(" + points + ")"
+		}
+		formatted[i] = fmt.Sprintf("{%s %s}", place, points)
+	}
+	return "[" + strings.Join(formatted, " ") + "]"
+}
+
+// WriteStandings writes the season standings table in the language of the catalog
+func WriteStandings(w io.Writer, catalog *i18n.Catalog, standings []Standing) error {
+	if _, err := fmt.Fprintln(w, catalog.SeasonHeader); err != nil {
+		return fmt.Errorf("WriteStandings: %w", err)
+	}
+	for _, s := range standings {
+		if _, err := fmt.Fprintf(w, "%d [%d] %d %s\n", s.Rank, s.Points, s.CompetitorID, formatResults(s.Results)); err != nil {
+			return fmt.Errorf("WriteStandings: %w", err)
+		}
+	}
+	return nil
+}
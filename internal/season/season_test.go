@@ -0,0 +1,97 @@
+package season
+
+import (
+	"TelecomTask/internal/i18n"
+	"TelecomTask/internal/process"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// reports builds resulting table of a race from total times in the order of competitor ids
+func reports(ids []int, totalTimes []string) []process.Report {
+	result := make([]process.Report, len(ids))
+	for i, id := range ids {
+		result[i] = process.Report{CompetitorID: id, TotalTime: totalTimes[i]}
+	}
+	return result
+}
+
+// TestCompute tests points, ties, dropped results and count-back, which takes all places including dropped ones
+func TestCompute(t *testing.T) {
+	table := &PointsTable{
+		Points:     []int{100, 80, 60, 50},
+		Categories: map[string][]int{"women": {2, 3}},
+	}
+	races := []Race{
+		{Name: "sprint", Reports: reports([]int{1, 2, 3, 4}, []string{"00:20:00.000", "00:20:00.000", "00:21:00.000", "NotFinished"})},
+		{Name: "pursuit", Reports: reports([]int{3, 1, 2}, []string{"00:30:00.000", "00:31:00.000", "00:32:00.000"})},
+	}
+
+	tests := []struct {
+		name      string
+		dropWorst int
+		category  string
+		expected  []Standing
+	}{
+		{
+			name: "all races",
+			expected: []Standing{
+				{Rank: 1, CompetitorID: 1, Points: 180, Results: []RaceResult{{Place: 1, Points: 100}, {Place: 2, Points: 80}}},
+				{Rank: 2, CompetitorID: 2, Points: 160, Results: []RaceResult{{Place: 1, Points: 100}, {Place: 3, Points: 60}}},
+				{Rank: 2, CompetitorID: 3, Points: 160, Results: []RaceResult{{Place: 3, Points: 60}, {Place: 1, Points: 100}}},
+				{Rank: 4, CompetitorID: 4, Points: 0, Results: []RaceResult{{}, {}}},
+			},
+		},
+		{
+			name:      "drop worst",
+			dropWorst: 1,
+			expected: []Standing{
+				{Rank: 1, CompetitorID: 1, Points: 100, Results: []RaceResult{{Place: 1, Points: 100}, {Place: 2, Points: 80, Dropped: true}}},
+				{Rank: 2, CompetitorID: 2, Points: 100, Results: []RaceResult{{Place: 1, Points: 100}, {Place: 3, Points: 60, Dropped: true}}},
+				{Rank: 2, CompetitorID: 3, Points: 100, Results: []RaceResult{{Place: 3, Points: 60, Dropped: true}, {Place: 1, Points: 100}}},
+				{Rank: 4, CompetitorID: 4, Points: 0, Results: []RaceResult{{}, {Dropped: true}}},
+			},
+		},
+		{
+			name:     "category",
+			category: "women",
+			expected: []Standing{
+				{Rank: 1, CompetitorID: 2, Points: 180, Results: []RaceResult{{Place: 1, Points: 100}, {Place: 2, Points: 80}}},
+				{Rank: 1, CompetitorID: 3, Points: 180, Results: []RaceResult{{Place: 2, Points: 80}, {Place: 1, Points: 100}}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table.DropWorst = test.dropWorst
+			standings, err := Compute(table, races, test.category)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(standings, test.expected) {
+				t.Errorf("Expected %+v, got %+v", test.expected, standings)
+			}
+		})
+	}
+
+	if _, err := Compute(table, races, "juniors"); err == nil {
+		t.Error("Expected error for unknown category")
+	}
+}
+
+// TestWriteStandings tests formatting of the standings table
+func TestWriteStandings(t *testing.T) {
+	catalog, _ := i18n.Get("en")
+	standings := []Standing{
+		{Rank: 1, CompetitorID: 3, Points: 100, Results: []RaceResult{{Place: 1, Points: 100}, {Place: 4, Points: 50, Dropped: true}, {}}},
+	}
+	var buf bytes.Buffer
+	if err := WriteStandings(&buf, catalog, standings); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := catalog.SeasonHeader + "\n1 [100] 3 [{1 100} {4 (50)} {- 0}]\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
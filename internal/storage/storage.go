@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/process"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS races (
+	id     INTEGER PRIMARY KEY AUTOINCREMENT,
+	name   TEXT NOT NULL UNIQUE,
+	config TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS events (
+	race_id INTEGER NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+	seq         INTEGER NOT NULL,
+	line        TEXT NOT NULL,
+	line_number INTEGER NOT NULL,
+	PRIMARY KEY (race_id, seq)
+);
+CREATE TABLE IF NOT EXISTS competitors (
+	race_id       INTEGER NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+	competitor_id INTEGER NOT NULL,
+	status        TEXT NOT NULL,
+	state         TEXT NOT NULL,
+	PRIMARY KEY (race_id, competitor_id)
+);
+CREATE TABLE IF NOT EXISTS results (
+	race_id         INTEGER NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+	competitor_id   INTEGER NOT NULL,
+	position        INTEGER,
+	total_time      TEXT NOT NULL,
+	lap_details     TEXT NOT NULL,
+	penalty_time    TEXT NOT NULL,
+	penalty_speed   REAL NOT NULL,
+	hits_shots      TEXT NOT NULL,
+	reason          TEXT NOT NULL,
+	adjusted        INTEGER NOT NULL,
+	start           TEXT NOT NULL,
+	scheduled_start TEXT NOT NULL,
+	actual_start    TEXT NOT NULL,
+	PRIMARY KEY (race_id, competitor_id)
+);
+CREATE INDEX IF NOT EXISTS results_competitor ON results (competitor_id);
+`
+
+// Store keeps races, their raw events, derived competitor state and results in SQLite database
+type Store struct {
+	db *sql.DB
+}
+
+// Result is the result of a competitor in one race
+type Result struct {
+	Race string
+	// Position is the place of the competitor among the finishers, 0 if the competitor did not finish
+	Position int
+	Report   process.Report
+}
+
+// Open opens database file or creates it with the schema
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("Open: error opening database: %w", err)
+	}
+	if _, err = db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("Open: error creating schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes database
+func (s *Store) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("Close: %w", err)
+	}
+	return nil
+}
+
+// SaveRace stores the race under the name, a race previously saved with the same name is replaced.
+// Only finished competitors get a position, the position of the rest is stored as NULL
+func (s *Store) SaveRace(name string, cfg *config.Config, events []process.Event,
+	competitors map[int]*process.Competitor, reports []process.Report) (err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("SaveRace: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	cfgJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("SaveRace: error encoding config: %w", err)
+	}
+	if _, err = tx.Exec(`DELETE FROM races WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("SaveRace: error replacing race: %w", err)
+	}
+	res, err := tx.Exec(`INSERT INTO races (name, config) VALUES (?, ?)`, name, string(cfgJSON))
+	if err != nil {
+		return fmt.Errorf("SaveRace: error inserting race: %w", err)
+	}
+	raceID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("SaveRace: %w", err)
+	}
+
+	for i, event := range events {
+		_, err = tx.Exec(`INSERT INTO events (race_id, seq, line, line_number) VALUES (?, ?, ?, ?)`,
+			raceID, i+1, event.String(), event.Line)
+		if err != nil {
+			return fmt.Errorf("SaveRace: error inserting event: %w", err)
+		}
+	}
+	ids := make([]int, 0, len(competitors))
+	for id := range competitors {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		state, err := json.Marshal(competitors[id])
+		if err != nil {
+			return fmt.Errorf("SaveRace: error encoding competitor state: %w", err)
+		}
+		_, err = tx.Exec(`INSERT INTO competitors (race_id, competitor_id, status, state) VALUES (?, ?, ?, ?)`,
+			raceID, id, competitors[id].Status, string(state))
+		if err != nil {
+			return fmt.Errorf("SaveRace: error inserting competitor: %w", err)
+		}
+	}
+	finished := 0
+	for _, report := range reports {
+		var position sql.NullInt64
+		if comp, ok := competitors[report.CompetitorID]; ok && comp.Status == "Finished" {
+			finished++
+			position = sql.NullInt64{Int64: int64(finished), Valid: true}
+		}
+		laps, err := json.Marshal(report.LapDetails)
+		if err != nil {
+			return fmt.Errorf("SaveRace: error encoding lap details: %w", err)
+		}
+		_, err = tx.Exec(`INSERT INTO results (race_id, competitor_id, position, total_time, lap_details, penalty_time, penalty_speed, hits_shots, reason, adjusted, start,
+			scheduled_start, actual_start)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			raceID, report.CompetitorID, position, report.TotalTime, string(laps), report.PenaltyTime, report.PenaltySpeed, report.HitsShots,
+			report.Reason, report.Adjusted, report.Start, report.ScheduledStart, report.ActualStart)
+		if err != nil {
+			return fmt.Errorf("SaveRace: error inserting result: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("SaveRace: %w", err)
+	}
+	return nil
+}
+
+// Races returns names of all stored races
+func (s *Store) Races() ([]string, error) {
+	rows, err := s.db.Query(`SELECT name FROM races ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("Races: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("Races: %w", err)
+		}
+		names = append(names, name)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("Races: %w", err)
+	}
+	return names, nil
+}
+
+// Events returns raw events of the race in the original order with their line numbers in the events file
+func (s *Store) Events(race string) ([]process.Event, error) {
+	rows, err := s.db.Query(`SELECT e.line, e.line_number FROM events e JOIN races r ON r.id = e.race_id WHERE r.name = ? ORDER BY e.seq`, race)
+	if err != nil {
+		return nil, fmt.Errorf("Events: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var events []process.Event
+	for rows.Next() {
+		var line string
+		var lineNumber int
+		if err = rows.Scan(&line, &lineNumber); err != nil {
+			return nil, fmt.Errorf("Events: %w", err)
+		}
+		event, err := process.ParseEvent(line)
+		if err != nil {
+			return nil, fmt.Errorf("Events: %w", err)
+		}
+		event.Line = lineNumber
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("Events: %w", err)
+	}
+	return events, nil
+}
+
+// Standings returns results of the race ordered by position, competitors who did not finish go last
+func (s *Store) Standings(race string) ([]Result, error) {
+	return s.results(`WHERE r.name = ? ORDER BY res.position IS NULL, res.position, res.rowid`, race)
+}
+
+// CompetitorResults returns results of the competitor in all stored races
+func (s *Store) CompetitorResults(competitorID int) ([]Result, error) {
+	return s.results(`WHERE res.competitor_id = ? ORDER BY r.id`, competitorID)
+}
+
+// results selects results with the condition
+func (s *Store) results(condition string, args ...interface{}) ([]Result, error) {
+	rows, err := s.db.Query(`SELECT r.name, res.position, res.competitor_id, res.total_time, res.lap_details,
+		res.penalty_time, res.penalty_speed, res.hits_shots, res.reason, res.adjusted, res.start, res.scheduled_start, res.actual_start
+		FROM results res JOIN races r ON r.id = res.race_id `+condition, args...)
+	if err != nil {
+		return nil, fmt.Errorf("results: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var results []Result
+	for rows.Next() {
+		var result Result
+		var position sql.NullInt64
+		var laps string
+		err = rows.Scan(&result.Race, &position, &result.Report.CompetitorID, &result.Report.TotalTime, &laps,
+			&result.Report.PenaltyTime, &result.Report.PenaltySpeed, &result.Report.HitsShots, &result.Report.Reason,
+			&result.Report.Adjusted, &result.Report.Start, &result.Report.ScheduledStart, &result.Report.ActualStart)
+		if err != nil {
+			return nil, fmt.Errorf("results: %w", err)
+		}
+		result.Position = int(position.Int64)
+		if err = json.Unmarshal([]byte(laps), &result.Report.LapDetails); err != nil {
+			return nil, fmt.Errorf("results: error decoding lap details: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("results: %w", err)
+	}
+	return results, nil
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"TelecomTask/internal/config"
+	"TelecomTask/internal/process"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testConfig = &config.Config{
+	Laps:        1,
+	LapLen:      1000,
+	PenaltyLen:  100,
+	FiringLines: 1,
+	Start:       "10:00:00.000",
+	StartDelta:  "00:00:30",
+}
+
+var testEvents = []process.Event{
+	{Time: "09:05:59.867", EventID: 1, CompetitorID: 1, ExtraParams: []string{}, Line: 1},
+	{Time: "09:15:00.841", EventID: 2, CompetitorID: 1, ExtraParams: []string{"10:00:00.000"}, Line: 3},
+}
+
+var testCompetitors = map[int]*process.Competitor{
+	1: {ID: 1, Status: "Finished", LapTimes: []time.Duration{5 * time.Minute}, Hits: map[int][]int{1: {1, 2}}, Shots: map[int]int{1: 5}},
+	2: {ID: 2, Status: "NotFinished", Hits: map[int][]int{}, Shots: map[int]int{}},
+}
+
+var finishedCompetitors = map[int]*process.Competitor{
+	1: {ID: 1, Status: "Finished", LapTimes: []time.Duration{6 * time.Minute}, Hits: map[int][]int{1: {1, 2}}, Shots: map[int]int{1: 5}},
+	2: {ID: 2, Status: "Finished", LapTimes: []time.Duration{4 * time.Minute}, Hits: map[int][]int{1: {1, 2}}, Shots: map[int]int{1: 5}},
+}
+
+// report builds report of the competitor with given total time
+func report(id int, totalTime string) process.Report {
+	return process.Report{
+		CompetitorID:   id,
+		TotalTime:      totalTime,
+		LapDetails:     []process.LapDetail{{Time: "00:05:00.000", Speed: 3.333}},
+		PenaltyTime:    "00:00:00.000",
+		HitsShots:      "2/5",
+		ScheduledStart: "10:00:00.000",
+		ActualStart:    "10:00:01.500",
+	}
+}
+
+// TestSaveRace tests storing races and querying results
+func TestSaveRace(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	rejected := report(2, "NotFinished")
+	rejected.Reason = process.ReasonRangesMissing
+	first := []process.Report{report(1, "00:05:00.000"), rejected}
+	adjusted := report(2, "00:04:00.000")
+	adjusted.Adjusted = true
+	adjusted.Start = process.StartLatePenalty
+	second := []process.Report{adjusted, report(1, "00:06:00.000")}
+	if err := store.SaveRace("sprint", testConfig, testEvents, testCompetitors, first); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.SaveRace("pursuit", testConfig, testEvents, finishedCompetitors, second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	races, err := store.Races()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(races, []string{"sprint", "pursuit"}) {
+		t.Errorf("Unexpected races: %v", races)
+	}
+
+	standings, err := store.Standings("pursuit")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(standings) != 2 || standings[0].Position != 1 || !reflect.DeepEqual(standings[0].Report, second[0]) {
+		t.Errorf("Unexpected standings: %v", standings)
+	}
+
+	standings, err = store.Standings("sprint")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(standings) != 2 || standings[0].Position != 1 || standings[1].Report.CompetitorID != 2 || standings[1].Position != 0 {
+		t.Errorf("Expected no position for the competitor who did not finish, got %v", standings)
+	}
+
+	results, err := store.CompetitorResults(1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].Race != "sprint" || results[0].Position != 1 || results[1].Race != "pursuit" || results[1].Position != 2 {
+		t.Errorf("Unexpected competitor results: %v", results)
+	}
+
+	events, err := store.Events("sprint")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[1].String() != testEvents[1].String() || events[1].Line != testEvents[1].Line {
+		t.Errorf("Unexpected events: %v", events)
+	}
+}
+
+// TestSaveRaceReplaces tests that saving a race with the same name replaces it
+func TestSaveRaceReplaces(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	if err := store.SaveRace("sprint", testConfig, testEvents, testCompetitors, []process.Report{report(1, "00:05:00.000")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.SaveRace("sprint", testConfig, testEvents[:1], testCompetitors, []process.Report{report(2, "00:04:00.000")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	standings, err := store.Standings("sprint")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(standings) != 1 || standings[0].Report.CompetitorID != 2 {
+		t.Errorf("Expected replaced standings, got %v", standings)
+	}
+	events, err := store.Events("sprint")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("Expected 1 event after replace, got %d", len(events))
+	}
+}
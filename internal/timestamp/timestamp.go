@@ -0,0 +1,107 @@
+package timestamp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// TimeOfDay is the layout of time without date, the date of such time is resolved from the previous events
+	TimeOfDay = "15:04:05.000"
+	// DateTime is the layout of time with date and without time zone, UTC is assumed
+	DateTime = "2006-01-02T15:04:05.000"
+	// DateTimeZone is the layout of time with date and time zone, it is RFC 3339 with milliseconds
+	DateTimeZone = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// Backward is how far before the reference time a time without date may be placed,
+// later times are taken as the next day. Out of order events must not be late by more than that
+const Backward = time.Hour
+
+// Parse parses time in one of the layouts: time of day, date and time or RFC 3339.
+// dated reports whether the time has date
+func Parse(s string) (t time.Time, dated bool, err error) {
+	t, dated, _, err = parse(s)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("Parse: %w", err)
+	}
+	return t, dated, nil
+}
+
+// parse parses time, zoned reports whether the time has explicit time zone
+func parse(s string) (t time.Time, dated, zoned bool, err error) {
+	if !strings.Contains(s, "T") {
+		t, err = time.Parse(TimeOfDay, s)
+		return t, false, false, err
+	}
+	if t, err = time.Parse(DateTime, s); err == nil {
+		return t, true, false, nil
+	}
+	t, err = time.Parse(time.RFC3339Nano, s)
+	return t, true, true, err
+}
+
+// Place moves time of day to the day of the reference time, so that it is not earlier than
+// Backward before the reference and less than a day after it. Zero reference leaves the time as it is
+func Place(t, ref time.Time) time.Time {
+	if ref.IsZero() {
+		return t
+	}
+	y, m, d := ref.Date()
+	placed := time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), ref.Location())
+	if placed.Before(ref.Add(-Backward)) {
+		placed = placed.AddDate(0, 0, 1)
+	} else if !placed.Before(ref.Add(24*time.Hour - Backward)) {
+		placed = placed.AddDate(0, 0, -1)
+	}
+	return placed
+}
+
+// Resolve parses time and places time of day after the reference time, so that a race crossing
+// midnight gets correct durations
+func Resolve(s string, ref time.Time) (time.Time, error) {
+	t, dated, err := Parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Resolve: %w", err)
+	}
+	if dated {
+		return t, nil
+	}
+	return Place(t, ref), nil
+}
+
+// ResolveIn works like Resolve, but times without zone are in the location
+func ResolveIn(s string, ref time.Time, location *time.Location) (time.Time, error) {
+	t, dated, zoned, err := parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ResolveIn: %w", err)
+	}
+	switch {
+	case zoned:
+		return t, nil
+	case dated:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), location), nil
+	case ref.IsZero():
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), location), nil
+	}
+	return Place(t, ref.In(location)), nil
+}
+
+// Format formats time with date in RFC 3339 or time of day only
+func Format(t time.Time, dated bool) string {
+	if dated {
+		return t.Format(DateTimeZone)
+	}
+	return t.Format(TimeOfDay)
+}
+
+// Day returns reference time which places times of day within the day of the time with date,
+// it is used before the first event. Time without date gives zero reference
+func Day(t time.Time, dated bool) time.Time {
+	if !dated {
+		return time.Time{}
+	}
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location()).Add(Backward)
+}
@@ -0,0 +1,64 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParse tests supported time layouts
+func TestParse(t *testing.T) {
+	msk := time.FixedZone("", 3*60*60)
+	tests := []struct {
+		input    string
+		expected time.Time
+		dated    bool
+		isError  bool
+	}{
+		{"09:05:59.867", time.Date(0, 1, 1, 9, 5, 59, 867e6, time.UTC), false, false},
+		{"2025-01-12T09:05:59.867", time.Date(2025, 1, 12, 9, 5, 59, 867e6, time.UTC), true, false},
+		{"2025-01-12T09:05:59.867+03:00", time.Date(2025, 1, 12, 9, 5, 59, 867e6, msk), true, false},
+		{"2025-01-12T09:05:59Z", time.Date(2025, 1, 12, 9, 5, 59, 0, time.UTC), true, false},
+		{"25:00:00.000", time.Time{}, false, true},
+		{"2025-01-12T", time.Time{}, false, true},
+	}
+	for _, test := range tests {
+		actual, dated, err := Parse(test.input)
+		if (err != nil) != test.isError {
+			t.Errorf("Parse(%q): unexpected error state: %v", test.input, err)
+			continue
+		}
+		if !actual.Equal(test.expected) || dated != test.dated {
+			t.Errorf("Parse(%q): expected %v %v, got %v %v", test.input, test.expected, test.dated, actual, dated)
+		}
+	}
+}
+
+// TestResolve tests placing time of day after the reference time
+func TestResolve(t *testing.T) {
+	day := time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		input    string
+		ref      time.Time
+		expected time.Time
+	}{
+		{"no reference", "23:59:00.000", time.Time{}, time.Date(0, 1, 1, 23, 59, 0, 0, time.UTC)},
+		{"same day", "10:00:00.000", day.Add(9 * time.Hour), day.Add(10 * time.Hour)},
+		{"after midnight", "00:02:00.000", day.Add(23*time.Hour + 59*time.Minute), day.Add(24*time.Hour + 2*time.Minute)},
+		{"slightly late", "23:58:00.000", day.Add(24*time.Hour + 2*time.Minute), day.Add(23*time.Hour + 58*time.Minute)},
+		{"long gap", "21:00:00.000", day.Add(8 * time.Hour), day.Add(21 * time.Hour)},
+		{"whole day", "23:30:00.000", Day(day, true), day.Add(23*time.Hour + 30*time.Minute)},
+		{"dated", "2025-01-14T01:00:00.000", day, day.Add(49 * time.Hour)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := Resolve(test.input, test.ref)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !actual.Equal(test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}